@@ -44,6 +44,15 @@ func logreaderConfig(confFile *string) logreader.LogReaderConfig {
 		}
 	}
 
+	if stdinPiped() {
+		configuration.Files = append([]logreader.LogFile{{LogFile: logreader.StdinPath, Name: "stdin"}}, configuration.Files...)
+	}
+
+	//Validated once the detected and piped files are added, they inherit the top level settings
+	if err := configuration.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
 	return configuration
 }
 
@@ -59,6 +68,10 @@ func logdisplayConfig(confFile *string) logdisplay.LogDisplayConfig {
 		log.Fatalf("Unmarshal: %v", err)
 	}
 
+	if err := configuration.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
 	if configuration.AutoDetectFiles == "Y" {
 		files := populateFilePaths()
 		for _, file := range files {
@@ -66,9 +79,19 @@ func logdisplayConfig(confFile *string) logdisplay.LogDisplayConfig {
 		}
 	}
 
+	if stdinPiped() {
+		configuration.Files = append([]logdisplay.LogFile{{LogFile: logreader.StdinPath, Name: "stdin"}}, configuration.Files...)
+	}
+
 	return configuration
 }
 
+//Returns true if a log is piped into golog, for example kubectl logs -f pod | golog
+func stdinPiped() bool {
+	fileInfo, err := os.Stdin.Stat()
+	return err == nil && fileInfo.Mode()&os.ModeCharDevice == 0
+}
+
 func populateFilePaths() []string{
 	var filePaths []string
 	var populateFromSubDirectories func (currentDirectory string)
@@ -87,7 +110,7 @@ func populateFilePaths() []string{
 				wd := currentDirectory+`/`+fileInfo.Name()
 				populateFromSubDirectories(wd)
 			} else {
-				if found, _ := regexp.MatchString(`.*\.log$`, fileInfo.Name()); found {
+				if found, _ := regexp.MatchString(`.*\.log(\.\d+)?(\.(gz|bz2|zst))?$`, fileInfo.Name()); found {
 					filePaths = append(filePaths, currentDirectory+`/`+fileInfo.Name())
 				}
 			}
@@ -3,8 +3,20 @@ package logdisplay
 import (
 	"fmt"
 	"regexp"
+	"strings"
+
+	"github.com/oskanaan/golog/logreader"
 )
 
+//Matches the escape sequences setting the colors of the text
+var colorSequence = regexp.MustCompile("\033\\[[0-9;]*m")
+
+//Escape sequence resetting the colors
+const colorReset = "\033[0m"
+
+//Reverse video used for the matches of the search term
+const matchHighlight = "\033[7m"
+
 //prepends/appends the header color to the log headers
 //Returns a color coded string
 func colorizeHeader(header string, logdisplayConfig *LogDisplayConfig) string {
@@ -17,10 +29,37 @@ func colorizeActive(text string, logdisplayConfig *LogDisplayConfig) string {
 	return fmt.Sprint(fmt.Sprintf("\033[3%d;%d;1m", 2, 4), text, "\033[0m")
 }
 
+//Colors of the source column in the merged view, used in turn by the files that don't configure their own
+var sourceColors = [][]interface{}{{6, 7}, {5, 7}, {4, 7}, {3, 7}, {2, 7}, {1, 7}}
+
+//prepends/appends the color of the file to the source column of the merged view
+//Returns a color coded string
+func colorizeSource(source string, fileIndex int, logdisplayConfig *LogDisplayConfig) string {
+	colorCode := sourceColors[fileIndex%len(sourceColors)]
+	if fileIndex < len(logdisplayConfig.Files) && len(logdisplayConfig.Files[fileIndex].Colors) > 0 {
+		colorCode = logdisplayConfig.Files[fileIndex].Colors
+	}
+
+	pre := fmt.Sprintf("\033[3%d;%d;1m", colorCode...)
+	return fmt.Sprint(pre, source, "\033[0m")
+}
+
+//The columns of a row and their headers, matched by the severity rules with a query
+type rowColumns struct {
+	headers []string
+	values  []string
+}
+
 //prepends/appends the color of the log entry based on the severity
 //Returns a color coded string
 func colorizeLogEntry(logEntry string, logdisplayConfig *LogDisplayConfig, highlight bool) string {
-	colorCode := severityColorCode(logEntry, logdisplayConfig)
+	return colorizeRow(logEntry, rowColumns{}, logdisplayConfig, highlight)
+}
+
+//prepends/appends the color of the formatted row based on the severity, the rules with a query are matched against its columns
+//Returns a color coded string
+func colorizeRow(logEntry string, columns rowColumns, logdisplayConfig *LogDisplayConfig, highlight bool) string {
+	colorCode := severityColorCode(logEntry, columns, logdisplayConfig)
 
 	if highlight {
 		colorCode = logdisplayConfig.Search.HighlightColor
@@ -30,9 +69,9 @@ func colorizeLogEntry(logEntry string, logdisplayConfig *LogDisplayConfig, highl
 	return fmt.Sprint(pre, logEntry, "\033[0m")
 }
 
-func severityColorCode(entry string, logdisplayConfig *LogDisplayConfig) []interface{} {
+func severityColorCode(entry string, columns rowColumns, logdisplayConfig *LogDisplayConfig) []interface{} {
 	for _, code := range logdisplayConfig.Severities {
-		if severityMatch(entry, code.Severity) {
+		if severityRuleMatch(code, entry, columns) {
 			colors := make([]interface{}, len(code.Colors))
 			for index, colorCode := range code.Colors {
 				colors[index] = colorCode
@@ -44,7 +83,71 @@ func severityColorCode(entry string, logdisplayConfig *LogDisplayConfig) []inter
 	return logdisplayConfig.Severities[len(logdisplayConfig.Severities) - 1].Colors
 }
 
-func severityMatch(logEntry, severityRegex string) bool {
-	match, _ := regexp.MatchString(severityRegex, logEntry)
-	return match
+//Returns true if the rule matches the row, by its query if it has one, by its severity regex otherwise
+//A query or regex that wasn't compiled by LogDisplayConfig.Validate doesn't match
+func severityRuleMatch(rule Severity, entry string, columns rowColumns) bool {
+	if rule.Query != "" {
+		return rule.query != nil && rule.query.MatchRow(columns.headers, columns.values)
+	}
+	return rule.regex != nil && rule.regex.MatchString(entry)
+}
+
+//Returns the style of the focused match, the search highlight color in reverse video
+func focusedMatchHighlight(logdisplayConfig *LogDisplayConfig) string {
+	if len(logdisplayConfig.Search.HighlightColor) == 0 {
+		return "\033[7;4m"
+	}
+	return fmt.Sprintf("\033[3%d;%d;1m", logdisplayConfig.Search.HighlightColor...) + matchHighlight
+}
+
+//Wraps every match of the search term in a line of colored text with the highlight, the color of the text resumes after each match
+//Escape sequences already in the line are skipped when matching
+//Returns the line with the matches highlighted
+func highlightMatches(line string, matcher logreader.Matcher, highlight string) string {
+	sequences := colorSequence.FindAllStringIndex(line, -1)
+	var text strings.Builder
+	//The position in the line of each byte of the text
+	positions := make([]int, 0, len(line))
+	next := 0
+	for _, sequence := range append(sequences, []int{len(line), len(line)}) {
+		for position := next; position < sequence[0]; position++ {
+			text.WriteByte(line[position])
+			positions = append(positions, position)
+		}
+		next = sequence[1]
+	}
+
+	//Returns the colors set by the sequences before the position in the line
+	styleAt := func(position int) string {
+		style := ""
+		for _, sequence := range sequences {
+			if sequence[1] > position {
+				break
+			}
+			if line[sequence[0]:sequence[1]] == colorReset {
+				style = ""
+			} else {
+				style += line[sequence[0]:sequence[1]]
+			}
+		}
+		return style
+	}
+
+	var highlighted strings.Builder
+	written := 0
+	for _, match := range matcher.FindAll(text.String()) {
+		if match[0] == match[1] {
+			continue
+		}
+		start, end := positions[match[0]], positions[match[1]-1]+1
+		highlighted.WriteString(line[written:start])
+		highlighted.WriteString(highlight)
+		highlighted.WriteString(line[start:end])
+		highlighted.WriteString(colorReset)
+		highlighted.WriteString(styleAt(end))
+		written = end
+	}
+	highlighted.WriteString(line[written:])
+
+	return highlighted.String()
 }
\ No newline at end of file
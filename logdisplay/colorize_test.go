@@ -3,6 +3,8 @@ package logdisplay
 import (
 	"fmt"
 	"testing"
+
+	"github.com/oskanaan/golog/logreader"
 )
 
 func TestColorize_colorizeHeader(t *testing.T) {
@@ -84,3 +86,79 @@ func TestColorize_colorizeLogEntry_unknownSeverityDefaultsToTrace(t *testing.T)
 		t.Errorf("Expected %s, got %s", expected, actual)
 	}
 }
+
+func TestColorize_colorizeSource(t *testing.T) {
+	config := logdisplayConfig()
+	config.Files = []LogFile{{Name: "api"}, {Name: "db", Colors: []interface{}{2, 4}}}
+
+	expected := fmt.Sprint(fmt.Sprintf("\033[3%d;%d;1m", 6, 7), "api", "\033[0m")
+	if actual := colorizeSource("api", 0, config); actual != expected {
+		t.Errorf("Expected %s, got %s", expected, actual)
+	}
+	expected = fmt.Sprint(fmt.Sprintf("\033[3%d;%d;1m", 2, 4), "db", "\033[0m")
+	if actual := colorizeSource("db", 1, config); actual != expected {
+		t.Errorf("Expected the configured color %s, got %s", expected, actual)
+	}
+}
+
+func TestColorize_highlightMatches_resumesEntryColor(t *testing.T) {
+	matcher, _ := logreader.NewMatcher("thread", logreader.SearchOptions{})
+	line := colorizeLogEntry("Thread-1\tERROR\tother thread", logdisplayConfig(), false)
+	entryColor := fmt.Sprintf("\033[3%d;%d;1m", 1, 1)
+
+	expected := entryColor + "\033[7mThread\033[0m" + entryColor + "-1\tERROR\tother \033[7mthread\033[0m" + entryColor + "\033[0m"
+	if actual := highlightMatches(line, matcher, matchHighlight); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+func TestColorize_highlightMatches_ignoresEscapeSequences(t *testing.T) {
+	matcher, _ := logreader.NewMatcher("31", logreader.SearchOptions{})
+	line := colorizeLogEntry("no match here", logdisplayConfig(), false)
+
+	if actual := highlightMatches(line, matcher, matchHighlight); actual != line {
+		t.Errorf("Expected the color codes not to match, got %q", actual)
+	}
+}
+
+func TestColorize_colorizeRow_queryRule(t *testing.T) {
+	config := logdisplayConfig()
+	config.Severities = []Severity{
+		{Query: "Severity=ERROR", Colors: []interface{}{5, 1}},
+		{Severity: `\bWARN\b`, Colors: []interface{}{3, 1}},
+		{Severity: `\bDEBUG\b`, Colors: []interface{}{0, 1}},
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	headers := []string{"Thread", "Severity", "Message"}
+
+	//ERROR in the message doesn't match the query, the WARN regex rule colors the row
+	entry := "Thread-1\tWARN\tERROR in the body"
+	expected := fmt.Sprint(fmt.Sprintf("\033[3%d;%d;1m", 3, 1), entry, "\033[0m")
+	if actual := colorizeRow(entry, rowColumns{headers, []string{"Thread-1", "WARN", "ERROR in the body"}}, config, false); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+
+	entry = "Thread-1\tERROR\tfailed"
+	expected = fmt.Sprint(fmt.Sprintf("\033[3%d;%d;1m", 5, 1), entry, "\033[0m")
+	if actual := colorizeRow(entry, rowColumns{headers, []string{"Thread-1", "ERROR", "failed"}}, config, false); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+func TestColorize_Validate_invalidQuery(t *testing.T) {
+	config := logdisplayConfig()
+	config.Files = []LogFile{{Name: "app", Severities: []Severity{{Query: "Severity=", Colors: []interface{}{1, 1}}}}}
+	if err := config.Validate(); err == nil {
+		t.Errorf("Expected an error for an invalid severity query")
+	}
+}
+
+func TestColorize_Validate_invalidRegex(t *testing.T) {
+	config := logdisplayConfig()
+	config.Severities = append(config.Severities, Severity{Severity: `(ERROR`, Colors: []interface{}{1, 1}})
+	if err := config.Validate(); err == nil {
+		t.Errorf("Expected an error for an invalid severity regex")
+	}
+}
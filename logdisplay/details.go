@@ -0,0 +1,239 @@
+package logdisplay
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jroimartin/gocui"
+	"github.com/oskanaan/golog/logreader"
+)
+
+const detailsSearchField = "detailsSearchField"
+
+//Style of the stack frames of the application in the details view
+const appFrameHighlight = "\033[33;1m"
+
+//Style of the lines naming an exception and its causes in the details view
+const exceptionHeaderHighlight = "\033[31;1m"
+
+//Title of the details view listing its keys
+const detailsTitle = "Entry details - arrows, PgUp/PgDn, Home/End scroll, / search, n/N next/previous, Esc close"
+
+//Splits the text into lines no wider than the width, tabs are expanded so the width is counted right
+//A width of 0 or less doesn't wrap the lines
+func wrapText(text string, width int) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.Replace(text, "\t", "    ", -1), "\n") {
+		runes := []rune(line)
+		for width > 0 && len(runes) > width {
+			lines = append(lines, string(runes[:width]))
+			runes = runes[width:]
+		}
+		lines = append(lines, string(runes))
+	}
+	return lines
+}
+
+//Returns the lines of the entry for the details view as they are in the log, with the style of each line
+//The lines naming the exception of a stack trace and its causes and the frames in the packages of the application are styled, the others have no style
+func detailsLines(entry string, appPackages []string) ([]string, []string) {
+	lines := strings.Split(entry, "\n")
+	styles := make([]string, len(lines))
+	for cause := logreader.ParseException(entry); cause != nil; cause = cause.Cause {
+		styles[cause.HeaderLine] = exceptionHeaderHighlight
+		for index, frame := range cause.Frames {
+			if frame.InPackages(appPackages) {
+				styles[cause.FrameLines[index]] = appFrameHighlight
+			}
+		}
+	}
+	return lines, styles
+}
+
+//Wraps the lines of the entry to the width of the details view, keeping the style of each line
+func (l *LogDisplay) setDetails(entry string, width int) {
+	lines, styles := detailsLines(entry, l.logdisplayConfig.AppPackages)
+	l.details, l.detailsStyles = nil, nil
+	for index, line := range lines {
+		for _, wrapped := range wrapText(line, width) {
+			l.details = append(l.details, wrapped)
+			l.detailsStyles = append(l.detailsStyles, styles[index])
+		}
+	}
+}
+
+//Finds the next line matching, starting at the line at the index and moving by step lines, continuing from the other end after the last line
+//Returns the index of the matching line, -1 if no line matches
+func findLine(lines []string, matcher logreader.Matcher, from int, step int) int {
+	for count := 0; count < len(lines); count++ {
+		index := ((from+step*count)%len(lines) + len(lines)) % len(lines)
+		if matcher.Match(lines[index]) {
+			return index
+		}
+	}
+	return -1
+}
+
+//Writes the lines of the entry to the details view in their styles, highlighting the matches of the search in the view
+func (l *LogDisplay) renderDetails(g *gocui.Gui) {
+	v, err := g.View(detailsView)
+	if err != nil {
+		return
+	}
+
+	v.Clear()
+	for index, line := range l.details {
+		if l.detailsStyles[index] != "" {
+			line = l.detailsStyles[index] + line + colorReset
+		}
+		if l.detailsMatcher != nil {
+			highlight := matchHighlight
+			if index == l.detailsMatch {
+				highlight = focusedMatchHighlight(l.logdisplayConfig)
+			}
+			line = highlightMatches(line, l.detailsMatcher, highlight)
+		}
+		fmt.Fprintln(v, line)
+	}
+}
+
+//Scrolls the details view so the line at the index is the first one shown, as far as there are lines to fill the view
+func (l *LogDisplay) scrollDetailsTo(g *gocui.Gui, line int) {
+	v, err := g.View(detailsView)
+	if err != nil {
+		return
+	}
+
+	_, height := v.Size()
+	if last := len(l.details) - height; line > last {
+		line = last
+	}
+	if line < 0 {
+		line = 0
+	}
+	v.SetOrigin(0, line)
+}
+
+//Returns the handler scrolling the details view by the number of lines, negative numbers scroll up
+func (l *LogDisplay) scrollDetails(lines int) func(g *gocui.Gui, v *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		_, origin := v.Origin()
+		l.scrollDetailsTo(g, origin+lines)
+		return nil
+	}
+}
+
+//Returns the handler scrolling the details view by the number of pages, negative numbers scroll up
+func (l *LogDisplay) scrollDetailsPage(pages int) func(g *gocui.Gui, v *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		_, origin := v.Origin()
+		_, height := v.Size()
+		l.scrollDetailsTo(g, origin+pages*height)
+		return nil
+	}
+}
+
+//Scrolls to the first line of the entry
+func (l *LogDisplay) detailsHome(g *gocui.Gui, v *gocui.View) error {
+	l.scrollDetailsTo(g, 0)
+	return nil
+}
+
+//Scrolls to the last line of the entry
+func (l *LogDisplay) detailsEnd(g *gocui.Gui, v *gocui.View) error {
+	l.scrollDetailsTo(g, len(l.details))
+	return nil
+}
+
+//Displays a box for entering a term to search in the details view
+func (l *LogDisplay) detailsSearch(g *gocui.Gui, v *gocui.View) error {
+	maxX, maxY := g.Size()
+	if v, err := g.SetView(detailsSearchField, 6, maxY-8, maxX-6, maxY-6); err != nil {
+		v.Wrap = false
+		v.Editable = true
+
+		v.Title = searchTitle(l.searchOptions)
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	g.SetCurrentView(detailsSearchField)
+	return nil
+}
+
+//Searches the details view for the term typed in the search box from its first shown line and scrolls to the match
+//Keeps the box open with the error or the lack of matches in its title
+func (l *LogDisplay) performDetailsSearch(g *gocui.Gui, v *gocui.View) error {
+	term, _ := v.Line(0)
+	matcher, err := logreader.NewMatcher(term, l.searchOptions)
+	if err != nil {
+		v.Title = err.Error()
+		return nil
+	}
+
+	details, err := g.View(detailsView)
+	if err != nil {
+		return err
+	}
+	_, origin := details.Origin()
+	match := findLine(l.details, matcher, origin, 1)
+	if match == -1 {
+		v.Title = "No match - " + searchTitle(l.searchOptions)
+		return nil
+	}
+
+	l.detailsMatcher = matcher
+	l.showDetailsMatch(g, match)
+	return l.exitDetailsSearch(g, v)
+}
+
+//Closes the search box of the details view
+func (l *LogDisplay) exitDetailsSearch(g *gocui.Gui, v *gocui.View) error {
+	if err := g.DeleteView(detailsSearchField); err != nil {
+		return err
+	}
+	g.SetCurrentView(detailsView)
+	return nil
+}
+
+//Scrolls to the next line matching the search in the details view
+func (l *LogDisplay) nextDetailsMatch(g *gocui.Gui, v *gocui.View) error {
+	if l.detailsMatcher != nil {
+		l.showDetailsMatch(g, findLine(l.details, l.detailsMatcher, l.detailsMatch+1, 1))
+	}
+	return nil
+}
+
+//Scrolls to the previous line matching the search in the details view
+func (l *LogDisplay) previousDetailsMatch(g *gocui.Gui, v *gocui.View) error {
+	if l.detailsMatcher != nil {
+		l.showDetailsMatch(g, findLine(l.details, l.detailsMatcher, l.detailsMatch-1, -1))
+	}
+	return nil
+}
+
+//Focuses the matching line at the index, scrolling it into view if it isn't shown
+func (l *LogDisplay) showDetailsMatch(g *gocui.Gui, match int) {
+	if match == -1 {
+		return
+	}
+	l.detailsMatch = match
+	l.renderDetails(g)
+
+	if v, err := g.View(detailsView); err == nil {
+		_, origin := v.Origin()
+		_, height := v.Size()
+		if match < origin || match >= origin+height {
+			l.scrollDetailsTo(g, match)
+		}
+	}
+}
+
+//Closes the details view and its search box, the keys go to the log again
+func (l *LogDisplay) closeDetails(g *gocui.Gui, v *gocui.View) error {
+	l.details = nil
+	l.detailsStyles = nil
+	l.detailsMatcher = nil
+	g.DeleteView(detailsSearchField)
+	return hideLogEntryDetails(g, v)
+}
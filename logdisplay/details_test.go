@@ -0,0 +1,68 @@
+package logdisplay
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/oskanaan/golog/logreader"
+)
+
+func TestDetails_wrapText(t *testing.T) {
+	expected := []string{"12/11/2010~Thread-2", "    at com.test.Fra", "me.call()", ""}
+	if actual := wrapText("12/11/2010~Thread-2\n\tat com.test.Frame.call()\n", 19); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+	if actual := wrapText("a long line", 0); !reflect.DeepEqual(actual, []string{"a long line"}) {
+		t.Errorf("Expected no wrapping without a width, got %q", actual)
+	}
+}
+
+func TestDetails_findLine(t *testing.T) {
+	lines := []string{"Exception", "at a", "Caused by: b", "at c", "Caused by: d"}
+	matcher, _ := logreader.NewMatcher("caused by", logreader.SearchOptions{})
+	cases := []struct {
+		from, step, expected int
+	}{
+		{0, 1, 2},
+		{3, 1, 4},
+		//Continues from the first line after the last one
+		{5, 1, 2},
+		{3, -1, 2},
+		{1, -1, 4},
+	}
+	for _, c := range cases {
+		if actual := findLine(lines, matcher, c.from, c.step); actual != c.expected {
+			t.Errorf("Expected line %d from %d by %d, got %d", c.expected, c.from, c.step, actual)
+		}
+	}
+
+	none, _ := logreader.NewMatcher("missing", logreader.SearchOptions{})
+	if actual := findLine(lines, none, 0, 1); actual != -1 {
+		t.Errorf("Expected no match, got %d", actual)
+	}
+}
+
+func TestDetails_detailsLines(t *testing.T) {
+	entry := "12/11/2010~Thread-2~com.test\n" +
+		"Exception in thread \"main\" java.lang.IllegalStateException: closed\n" +
+		"\tat com.test.Service.run(Service.java:12)\n" +
+		"\tat java.lang.Thread.run(Thread.java:841)\n" +
+		"\tSuppressed: java.io.IOException: flush failed\n" +
+		"\t\tat com.test.Store.flush(Store.java:30)\n" +
+		"Caused by: java.io.IOException\n" +
+		"\tat com.test.Store.read(Store.java:40)\n" +
+		"\t... 1 more"
+	lines, styles := detailsLines(entry, []string{"com.test."})
+	if expected := strings.Split(entry, "\n"); !reflect.DeepEqual(lines, expected) {
+		t.Errorf("Expected the lines as they are in the log %q, got %q", expected, lines)
+	}
+	expected := []string{"", exceptionHeaderHighlight, appFrameHighlight, "", "", "", exceptionHeaderHighlight, appFrameHighlight, ""}
+	if !reflect.DeepEqual(styles, expected) {
+		t.Errorf("Expected the styles %q, got %q", expected, styles)
+	}
+
+	if lines, styles := detailsLines("12/11/2010~Thread-2~com.test\nplain text", nil); len(lines) != 2 || len(styles) != 2 || styles[1] != "" {
+		t.Errorf("Expected an entry without a trace unchanged, got %q", lines)
+	}
+}
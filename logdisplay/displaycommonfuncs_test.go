@@ -4,25 +4,27 @@ import "github.com/oskanaan/golog/logreader"
 
 func logreaderConfig(file string, sizes []int) logreader.LogReaderConfig{
 	return logreader.LogReaderConfig{
-		Files: []logreader.LogFile{{file, "Name"}},
+		Files: []logreader.LogFile{{LogFile: file, Name: "Name"}},
 		Seperator: "~",
 		Headers: []logreader.Header{
-			{"Date", sizes[0]},
-			{"Thread", sizes[1]},
-			{"Package", sizes[2]},
+			{Header: "Date", Size: sizes[0]},
+			{Header: "Thread", Size: sizes[1]},
+			{Header: "Package", Size: sizes[2]},
 		},
 	}
 }
 
 func logdisplayConfig() *LogDisplayConfig{
-	return &LogDisplayConfig{
+	config := &LogDisplayConfig{
 		Severities: []Severity {
-			{`\bERROR\b`, []interface{}{1, 1}},
-			{`\bWARN\b`, []interface{}{3, 1}},
-			{`\bTRACE\b`, []interface{}{6, 5}},
-			{`\bINFO\b`, []interface{}{2, 1}},
-			{`\bDEBUG\b`, []interface{}{0, 1}},
+			{Severity: `\bERROR\b`, Colors: []interface{}{1, 1}},
+			{Severity: `\bWARN\b`, Colors: []interface{}{3, 1}},
+			{Severity: `\bTRACE\b`, Colors: []interface{}{6, 5}},
+			{Severity: `\bINFO\b`, Colors: []interface{}{2, 1}},
+			{Severity: `\bDEBUG\b`, Colors: []interface{}{0, 1}},
 		},
 	}
+	config.Validate()
+	return config
 }
 
@@ -0,0 +1,158 @@
+package logdisplay
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jroimartin/gocui"
+	"github.com/oskanaan/golog/logreader"
+)
+
+const exceptionsView = "exceptions"
+
+//Title of the exceptions view listing its keys
+const exceptionsTitle = "Exceptions by count - arrows select, Enter or click goes to the first one, Esc close"
+
+//Number of top frames in the fingerprint of a stack trace if the configuration doesn't set it
+const defaultExceptionFrames = 3
+
+//Returns the rows of the exceptions view, the count, the first and last time seen and the exception with the frame it was thrown at
+//The lines of the first and last entries are shown if the log has no timestamps
+func exceptionRows(groups []logreader.ExceptionGroup) []string {
+	if len(groups) == 0 {
+		return []string{"No stack traces found"}
+	}
+
+	rows := make([]string, len(groups))
+	for index, group := range groups {
+		exception := group.Exception.Type
+		if group.Exception.Message != "" {
+			exception += ": " + group.Exception.Message
+		}
+		if len(group.Exception.Frames) > 0 {
+			exception += " at " + group.Exception.Frames[0].String()
+		}
+		rows[index] = fmt.Sprintf("%6d  %-19s  %-19s  %s", group.Count, seenAt(group.FirstSeen, group.FirstLine), seenAt(group.LastSeen, group.LastLine), exception)
+	}
+	return rows
+}
+
+//Returns the time an entry was seen, or its line if it has no time
+func seenAt(seen time.Time, line int) string {
+	if seen.IsZero() {
+		return fmt.Sprintf("line %d", line)
+	}
+	return seen.Format("2006-01-02 15:04:05")
+}
+
+//Groups the stack traces of the active file in the background and lists the groups in a popup window that takes the keys until it is closed
+//The popup shows the groups are being counted until they are read
+func (l *LogDisplay) showExceptions(g *gocui.Gui, v *gocui.View) error {
+	frames := l.logdisplayConfig.ExceptionFrames
+	if frames <= 0 {
+		frames = defaultExceptionFrames
+	}
+	l.exceptionGroups = nil
+	l.listScans++
+	scan := l.listScans
+
+	maxX, maxY := g.Size()
+	exceptions, err := g.SetView(exceptionsView, 5, 5, maxX-5, maxY-5)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	exceptions.Wrap = false
+	exceptions.Editable = false
+	exceptions.Highlight = true
+	exceptions.SelBgColor = gocui.ColorWhite
+	exceptions.SelFgColor = gocui.ColorBlack
+	exceptions.Title = fmt.Sprintf("%s (%s)", exceptionsTitle, countingText)
+	exceptions.Clear()
+	fmt.Fprintln(exceptions, "Counting the stack traces "+countingText)
+	exceptions.SetOrigin(0, 0)
+	exceptions.SetCursor(0, 0)
+
+	l.logReader.ExceptionGroups(frames, func(groups []logreader.ExceptionGroup) {
+		g.Update(func(g *gocui.Gui) error {
+			//The popup was closed or opened again while the groups were counted
+			exceptions, err := g.View(exceptionsView)
+			if scan != l.listScans || err != nil {
+				return nil
+			}
+			l.exceptionGroups = groups
+			exceptions.Title = fmt.Sprintf("%s (%d)", exceptionsTitle, len(groups))
+			exceptions.Clear()
+			for _, row := range exceptionRows(groups) {
+				fmt.Fprintln(exceptions, row)
+			}
+			return nil
+		})
+	})
+	_, err = g.SetCurrentView(exceptionsView)
+	return err
+}
+
+//Moves the selection in a list view of the number of rows by the rows given, negative numbers move up, scrolling the selected row into view
+func moveSelection(v *gocui.View, rows int, count int) {
+	_, origin := v.Origin()
+	_, cursor := v.Cursor()
+	_, height := v.Size()
+	selected := origin + cursor + rows
+	if selected >= count {
+		selected = count - 1
+	}
+	if selected < 0 {
+		selected = 0
+	}
+
+	if selected < origin {
+		origin = selected
+	} else if selected >= origin+height {
+		origin = selected - height + 1
+	}
+	v.SetOrigin(0, origin)
+	v.SetCursor(0, selected-origin)
+}
+
+//Returns the index of the selected row of a list view
+func selectedRow(v *gocui.View) int {
+	_, origin := v.Origin()
+	_, cursor := v.Cursor()
+	return origin + cursor
+}
+
+//Returns the handler moving the selection in the exceptions view by the number of rows, negative numbers move up
+func (l *LogDisplay) selectException(rows int) func(g *gocui.Gui, v *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		moveSelection(v, rows, len(l.exceptionGroups))
+		return nil
+	}
+}
+
+//Closes the exceptions view and shows the first entry of the selected group at the top of the page
+func (l *LogDisplay) goToException(g *gocui.Gui, v *gocui.View) error {
+	selected := selectedRow(v)
+	if selected >= len(l.exceptionGroups) {
+		return nil
+	}
+
+	l.tailOn[l.logFileIndex] = &[]bool{false}[0]
+	l.exitSearchMode()
+	l.currentPage = l.logReader.SeekLine(l.exceptionGroups[selected].FirstLine)
+	if err := l.closeExceptions(g, v); err != nil {
+		return err
+	}
+	l.rerender(g)
+	return nil
+}
+
+//Closes the exceptions view, the keys go to the log again
+func (l *LogDisplay) closeExceptions(g *gocui.Gui, v *gocui.View) error {
+	l.exceptionGroups = nil
+	l.listScans++
+	if err := g.DeleteView(exceptionsView); err != nil {
+		return err
+	}
+	_, err := g.SetCurrentView(mainView)
+	return err
+}
@@ -0,0 +1,30 @@
+package logdisplay
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/oskanaan/golog/logreader"
+)
+
+func TestExceptions_exceptionRows(t *testing.T) {
+	groups := []logreader.ExceptionGroup{
+		{
+			Exception: &logreader.Exception{Type: "java.lang.IllegalStateException", Message: "closed", Frames: []logreader.StackFrame{{Function: "com.test.Store.read", File: "Store.java", Line: 10}}},
+			Count:     12, FirstLine: 3, LastLine: 90,
+			FirstSeen: time.Date(2010, 11, 11, 10, 0, 0, 0, time.UTC), LastSeen: time.Date(2010, 11, 12, 8, 30, 0, 0, time.UTC),
+		},
+		{Exception: &logreader.Exception{Type: "KeyError"}, Count: 1, FirstLine: 40, LastLine: 40},
+	}
+	expected := []string{
+		"    12  2010-11-11 10:00:00  2010-11-12 08:30:00  java.lang.IllegalStateException: closed at com.test.Store.read(Store.java:10)",
+		"     1  line 40              line 40              KeyError",
+	}
+	if actual := exceptionRows(groups); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+	if actual := exceptionRows(nil); len(actual) != 1 {
+		t.Errorf("Expected a row saying there are no stack traces, got %q", actual)
+	}
+}
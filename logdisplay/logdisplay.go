@@ -7,50 +7,152 @@ import (
 	"github.com/jroimartin/gocui"
 	"github.com/oskanaan/golog/logreader"
 	"log"
+	"os"
+	"os/signal"
+	"regexp"
 	"sync"
+	"syscall"
 	"text/tabwriter"
 	"time"
 	"github.com/atotto/clipboard"
 	"strconv"
+	"strings"
+	"errors"
 )
 
 var wg sync.WaitGroup
 const detailsView = "details"
 const searchField = "searchField"
+const goToField = "goToField"
+const goToTimeField = "goToTimeField"
 const progress = "progress"
 const tailIndicator = "tailIndicator"
 const searchButton = "searchButton"
 const mainView = "mainView"
+const rotationIndicator = "rotationIndicator"
+const mergedTab = "mergedTab"
+const matchCounter = "matchCounter"
+const filterField = "filterField"
+const filterIndicator = "filterIndicator"
+const levelsIndicator = "levelsIndicator"
+const jumpIndicator = "jumpIndicator"
+
+//Shown in place of the matches counted in the background until they are counted
+const countingText = "…"
+
+//How long the rotation marker stays visible after a rotation or truncation was detected
+const rotationNoticeDuration = 30 * time.Second
 
 type LogDisplayConfig struct {
 	AutoDetectFiles     string `yaml:"autoDetectFiles"`
 	Severities []Severity
 	Files      []LogFile `yaml:files`
 	Search     Search    `yaml:search`
+	//The stack frames with a function or file starting with one of these prefixes are highlighted in the details view
+	AppPackages []string `yaml:"appPackages"`
+	//Number of top frames that must match for stack traces to be counted together in the exceptions view, 3 if not set
+	ExceptionFrames int `yaml:"exceptionFrames"`
+	//The column clustered into patterns, files without it use Message if they have it, their last column otherwise
+	PatternColumn string `yaml:"patternColumn"`
 }
 
+//A log file tab, the severities override the top level ones for this file only
+//The colors are used for the name of the file in the source column of the merged view
 type LogFile struct {
 	LogFile string `yaml:"file"`
 	Name string `yaml:"name"`
+	Severities []Severity `yaml:"severities"`
+	Colors []interface{} `yaml:"colors"`
 }
 
+//A coloring rule, the severity regex is matched against the whole row, a query against its columns (Severity=ERROR)
+//The rules of a file are also the levels toggled with the number keys in its tab, the name labels the level
 type Severity struct {
+	Name string `yaml:"name"`
 	Severity string `yaml:"severity"`
+	Query string `yaml:"query"`
 	Colors []interface{} `yaml:"colors"`
+	query *logreader.Query
+	regex *regexp.Regexp
 }
 
 type Search struct {
 	HighlightColor []interface{} `yaml:"highlightColor"`
 }
 
+//How the number typed in the go to box is interpreted
+const (
+	goToLine = iota
+	goToPercent
+	goToRelative
+)
+
+//A position typed in the go to box: a line number (12345), a percentage of the file (50%) or a number of lines to move (+200, -200)
+type goToTarget struct {
+	kind  int
+	value int
+}
+
 type LogDisplay struct {
 	logReader   *logreader.LogReader
 	currentPage *[][]string
 	searchResultLocation int
 	tailOn      []*bool
 	searchOn    *bool
+	searchOptions logreader.SearchOptions
+	searchMatcher logreader.Matcher
+	filters     [][]logreader.Filter
+	hiddenSeverities [][]bool
+	jumpLevel   int
+	levelMatcher logreader.Matcher
+	levelJumps  bool
+	details     []string
+	detailsStyles []string
+	exceptionGroups []logreader.ExceptionGroup
+	patterns       []logreader.Pattern
+	patternsColumn string
+	//Counts the exceptions and patterns popups opened and closed, the lists read in the background for a popup since closed are dropped
+	listScans   int
+	detailsMatcher logreader.Matcher
+	detailsMatch int
 	logFileIndex int
 	logdisplayConfig *LogDisplayConfig
+	rotationNotice string
+	rotationNoticeTime time.Time
+	newLines    []int
+}
+
+//Compiles the queries and regexes of the severity rules
+//Returns an error if a query or regex is invalid
+func (c *LogDisplayConfig) Validate() error {
+	if err := compileSeverities(c.Severities); err != nil {
+		return err
+	}
+	for _, file := range c.Files {
+		if err := compileSeverities(file.Severities); err != nil {
+			return fmt.Errorf("file %q: %v", file.Name, err)
+		}
+	}
+	return nil
+}
+
+func compileSeverities(severities []Severity) error {
+	for index := range severities {
+		if severities[index].Query == "" {
+			regex, err := regexp.Compile(severities[index].Severity)
+			if err != nil {
+				return fmt.Errorf("severity rule %d: invalid regex: %v", index+1, strings.TrimPrefix(err.Error(), "error parsing regexp: "))
+			}
+			severities[index].regex = regex
+			continue
+		}
+		query, err := logreader.ParseQuery(severities[index].Query, logreader.SearchOptions{})
+		if err != nil {
+			return fmt.Errorf("severity rule %d: %v", index+1, err)
+		}
+		severities[index].query = query
+	}
+	return nil
 }
 
 //Returns a new instance of a LogDisplay
@@ -61,11 +163,20 @@ func NewLogDisplay(logReader *logreader.LogReader, logdisplayConfig *LogDisplayC
 
 
 
-	l.tailOn = make([]*bool, len(l.logdisplayConfig.Files))
-	for index := 0 ; index < len(l.logdisplayConfig.Files) ; index ++ {
+	//The extra slot is for the merged view, which has the index after the last file
+	l.tailOn = make([]*bool, len(l.logdisplayConfig.Files) + 1)
+	for index := 0 ; index < len(l.tailOn) ; index ++ {
 		l.tailOn[index] = &[]bool{true}[0]
 	}
+	l.newLines = make([]int, len(l.logdisplayConfig.Files) + 1)
+	l.filters = make([][]logreader.Filter, len(l.logdisplayConfig.Files) + 1)
 	l.searchResultLocation = -1
+	l.searchOn = &[]bool{false}[0]
+	//Each file toggles the levels of its own severity rules
+	l.hiddenSeverities = make([][]bool, len(l.logdisplayConfig.Files) + 1)
+	for index := range l.hiddenSeverities {
+		l.hiddenSeverities[index] = make([]bool, len(l.fileConfig(index).Severities))
+	}
 	return l
 }
 
@@ -75,9 +186,22 @@ func (l *LogDisplay) DisplayUI() {
 	if err != nil {
 		log.Panicln(err)
 	}
+	//The temp files of the reader are removed however the UI ends, a signal quits it like the quit key
+	defer l.logReader.Close()
 	defer g.Close()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(signals)
+	go func() {
+		<-signals
+		g.Update(func(g *gocui.Gui) error {
+			return quit(g, nil)
+		})
+	}()
 	g.Cursor = true
 	g.Mouse = true
+	//Report the escape key on its own, it clears the search
+	g.InputEsc = true
 	//l.tail()
 
 	g.SetManagerFunc(l.layout)
@@ -86,25 +210,12 @@ func (l *LogDisplay) DisplayUI() {
 		log.Panicln(err)
 	}
 
+	//The new lines of every file are counted from its size when following starts, even the tabs never opened
+	for index := range l.logdisplayConfig.Files {
+		l.logReader.MarkSeen(index)
+	}
 	wg.Add(1)
-	go func(g *gocui.Gui, l *LogDisplay) {
-		for {
-			if *l.tailOn[l.logFileIndex] {
-
-				g.Update(func(g *gocui.Gui) error {
-					_, err := g.View(mainView)
-					if err != nil {
-						return err
-					}
-					l.currentPage = l.logReader.Tail()
-					l.rerender(g)
-					return nil
-				})
-			}
-
-			time.Sleep(1 * time.Second)
-		}
-	}(g, l)
+	go l.followChanges(g)
 
 	if err := g.MainLoop(); err != nil && err != gocui.ErrQuit {
 		log.Panicln(err)
@@ -113,6 +224,66 @@ func (l *LogDisplay) DisplayUI() {
 	wg.Wait()
 }
 
+//Tails the active file whenever it changes and counts the new lines of the inactive files for their tabs
+//Changes are reported by file system notifications where available, otherwise the files are polled
+//The page is read again as the views the reader copies in the background grow, such as the filtered view
+func (l *LogDisplay) followChanges(g *gocui.Gui) {
+	watcher := logreader.NewLogWatcher(l.logReader.FilePaths())
+	defer watcher.Close()
+
+	g.Update(l.refreshTail)
+	for {
+		select {
+		case <-watcher.Notify:
+			changed := watcher.Changed()
+			g.Update(func(g *gocui.Gui) error {
+				for _, index := range changed {
+					//The merged view shows the changes of every file, there are no unseen lines to count
+					if index != l.logFileIndex && !l.merged() {
+						l.newLines[index] = l.logReader.NewLines(index)
+					}
+				}
+				l.renderFileTabs(g)
+				return l.refreshTail(g)
+			})
+		case <-l.logReader.Updates():
+			g.Update(l.refreshPage)
+		}
+	}
+}
+
+//Tails the active file if tailing is on
+//Returns an error if the main view cannot be found
+func (l *LogDisplay) refreshTail(g *gocui.Gui) error {
+	if !*l.tailOn[l.logFileIndex] {
+		return nil
+	}
+
+	_, err := g.View(mainView)
+	if err != nil {
+		return err
+	}
+	l.tail()
+	l.rerender(g)
+	return nil
+}
+
+//Tails the active file if tailing is on, otherwise reads the current page again
+//Returns an error if the main view cannot be found
+func (l *LogDisplay) refreshPage(g *gocui.Gui) error {
+	if *l.tailOn[l.logFileIndex] {
+		return l.refreshTail(g)
+	}
+
+	_, err := g.View(mainView)
+	if err != nil {
+		return err
+	}
+	l.currentPage = l.logReader.Refresh()
+	l.rerender(g)
+	return nil
+}
+
 //Prints the log to the stdout, used for debugging purposes only
 func (l LogDisplay) DisplayStdout() {
 	l.logReader.SetCapacity(50)
@@ -146,6 +317,46 @@ func (l LogDisplay) layout(g *gocui.Gui) error {
 		}
 	}
 
+	if v, err := g.SetView(matchCounter, maxX-32, maxY-3, maxX-17, maxY-1); err != nil {
+		v.Wrap = false
+		v.Editable = true
+
+		v.Title = "Matches"
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+
+	if v, err := g.SetView(jumpIndicator, maxX-102, maxY-3, maxX-82, maxY-1); err != nil {
+		v.Wrap = false
+		v.Editable = true
+
+		v.Title = "e/E, s level"
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+
+	if v, err := g.SetView(levelsIndicator, maxX-80, maxY-3, maxX-51, maxY-1); err != nil {
+		v.Wrap = false
+		v.Editable = true
+
+		v.Title = levelsTitle(len(l.activeFileConfig().Severities))
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+
+	if v, err := g.SetView(filterIndicator, maxX-49, maxY-3, maxX-34, maxY-1); err != nil {
+		v.Wrap = false
+		v.Editable = true
+
+		v.Title = "Filters"
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+
 	if v, err := g.SetView(tailIndicator, maxX-15, maxY-3, maxX-8, maxY-1); err != nil {
 		v.Wrap = false
 		v.Editable = true
@@ -177,24 +388,53 @@ func (l LogDisplay) layout(g *gocui.Gui) error {
 		}
 	}
 
+	//The merged view of all files is only offered when there is more than one
+	if len(l.logdisplayConfig.Files) > 1 {
+		x0 := 4 + (20 * len(l.logdisplayConfig.Files))
+		if v, err := g.SetView(mergedTab, x0, maxY - 3, x0 + 15 , maxY - 1); err != nil {
+			v.Wrap = false
+			v.Editable = true
+			v.Title = "F12"
+			fmt.Fprint(v, "Merged")
+			if err != gocui.ErrUnknownView {
+				return err
+			}
+		}
+	}
+
 	return nil
 }
 
 //Writes the log data to a string
 //The data will be read from the logReader and displayed in a column format using tabwriter
 //Returns a string containing the current log page formatted using tabwriter
+//Every match of the last search is highlighted until the search is cleared, the match the search navigated to is styled differently
 func (l LogDisplay) formattedLog() string {
 	tabWriter := new(tabwriter.Writer)
 	var output bytes.Buffer
 	tabWriter.Init(&output, 0, 8, 0, '\t', tabwriter.TabIndent)
 	l.writeHeader(tabWriter)
-	l.writeBody(tabWriter)
+	focusedLine := l.writeBody(tabWriter)
 	tabWriter.Flush()
 
-	return output.String()
+	if l.searchMatcher == nil {
+		return output.String()
+	}
+
+	//The matches are highlighted after the columns are aligned, the escape sequences would count in the width of the columns otherwise
+	lines := strings.Split(output.String(), "\n")
+	for index := 1; index < len(lines); index++ {
+		highlight := matchHighlight
+		if index == focusedLine + 1 {
+			highlight = focusedMatchHighlight(l.logdisplayConfig)
+		}
+		lines[index] = highlightMatches(lines[index], l.searchMatcher, highlight)
+	}
+	return strings.Join(lines, "\n")
 }
 
 //Writes the header of the log file.
+//The source column of the merged view is colored on its own to stay aligned with the rows, whose source is colored by file
 func (l LogDisplay) writeHeader(writer *tabwriter.Writer) {
 	var header string
 	for index, columnHeader := range l.logReader.GetHeaders() {
@@ -203,24 +443,31 @@ func (l LogDisplay) writeHeader(writer *tabwriter.Writer) {
 			header = header + "\t"
 		}
 	}
+	if source := strings.Index(header, "\t"); l.merged() && source != -1 {
+		fmt.Fprintln(writer, colorizeHeader(header[:source], l.logdisplayConfig) + "\t" + colorizeHeader(header[source+1:], l.logdisplayConfig))
+		return
+	}
 	fmt.Fprintln(writer, colorizeHeader(header, l.logdisplayConfig))
 }
 
 //Writes the formatted current page of the log to a tabwriter
-func (l LogDisplay) writeBody(tabWriter *tabwriter.Writer) {
+//In the merged view the source column is colored by file and the rest of the row by severity
+//Returns the number of the written line showing the search result, -1 if it isn't on the page
+func (l LogDisplay) writeBody(tabWriter *tabwriter.Writer) int {
+	focusedLine := -1
+	written := 0
+	headers := l.logReader.GetHeaders()
 	for index, row := range *l.currentPage {
 		var rowText string
+		var source string
 		//If this is a stack trace or some debugging information then no parsing is needed, display as is
 		if len(row) == 1 {
 			rowText = row[0]
+		} else if l.merged() {
+			source = colorizeSource(l.formatColumnText(row[0], 0), l.sourceIndex(row[0]), l.logdisplayConfig) + "\t"
+			rowText = l.rowText(row[1:], 1)
 		} else {
-			for index, columnText := range row {
-				formattedColText := l.formatColumnText(columnText, index)
-				rowText = rowText + formattedColText
-				if index < len(columnText)-1 {
-					rowText = rowText + "\t"
-				}
-			}
+			rowText = l.rowText(row, 0)
 		}
 
 		if rowText == "" {
@@ -228,16 +475,70 @@ func (l LogDisplay) writeBody(tabWriter *tabwriter.Writer) {
 		}
 
 		if index == l.searchResultLocation {
-			fmt.Fprintln(tabWriter, colorizeLogEntry(rowText, l.logdisplayConfig, true))
+			focusedLine = written
+			fmt.Fprintln(tabWriter, source + colorizeRow(rowText, rowColumns{headers, row}, l.activeFileConfig(), true))
 		} else {
-			fmt.Fprintln(tabWriter, colorizeLogEntry(rowText, l.logdisplayConfig, false))
+			fmt.Fprintln(tabWriter, source + colorizeRow(rowText, rowColumns{headers, row}, l.activeFileConfig(), false))
 		}
+		written++
 	}
+
+	return focusedLine
+}
+
+//Formats the columns of a row, the first column is formatted with the size of the header at the index
+func (l LogDisplay) rowText(row []string, firstColumn int) string {
+	var rowText string
+	for index, columnText := range row {
+		formattedColText := l.formatColumnText(columnText, index + firstColumn)
+		rowText = rowText + formattedColText
+		if index < len(columnText)-1 {
+			rowText = rowText + "\t"
+		}
+	}
+	return rowText
+}
+
+//Returns true if the merged view of all files is shown
+func (l LogDisplay) merged() bool {
+	return len(l.logdisplayConfig.Files) > 0 && l.logFileIndex == l.logReader.MergedIndex()
+}
+
+//Returns the index of the file with the name shown in the source column of the merged view
+func (l LogDisplay) sourceIndex(name string) int {
+	for index, file := range l.logdisplayConfig.Files {
+		if file.Name == name {
+			return index
+		}
+	}
+	return 0
+}
+
+//Returns the display configuration for the active file, using the file's severities if it has its own
+func (l LogDisplay) activeFileConfig() *LogDisplayConfig {
+	return l.fileConfig(l.logFileIndex)
+}
+
+//Returns the display configuration for the file at the index, using the file's severities if it has its own
+//The merged view uses the top level severities
+func (l LogDisplay) fileConfig(index int) *LogDisplayConfig {
+	if index >= len(l.logdisplayConfig.Files) || len(l.logdisplayConfig.Files[index].Severities) == 0 {
+		return l.logdisplayConfig
+	}
+
+	config := *l.logdisplayConfig
+	config.Severities = l.logdisplayConfig.Files[index].Severities
+	return &config
 }
 
 //Returns the tail data based on the "capacity" configuration passed to the program
+//Remembers when the reader detected that the file was rotated or truncated so the marker can be shown
 func (l *LogDisplay) tail() {
 	l.currentPage = l.logReader.Tail()
+	if notice := l.logReader.RotationNotice(); notice != "" {
+		l.rotationNotice = notice
+		l.rotationNoticeTime = time.Now()
+	}
 }
 
 //Applies column formatting based on the program parameters.
@@ -271,13 +572,27 @@ func (l LogDisplay) formatColumnText(text string, columnIndex int) string {
 
 //Binds keyboard keys and mouse buttons to actions
 //CTRL-C : quit
-//Mouse Left: show log entry details
-//Page Down: scroll one page down
+//Mouse Left: show the whole log entry in the details view
+//In the details view arrows, Page Up/Down and Home/End scroll, / searches, n/N go to the next/previous match, Esc, q and Mouse Right close it
+//Page Down: scroll one page down, pages end with whole entries
 //Page Up: scroll one page up
-//Arrow Down: scroll down
-//Arrow Up: scroll up
+//Arrow Down: scroll down by one entry and its continuation lines
+//Arrow Up: scroll up by one entry and its continuation lines
 //Key home: navigates to the beginning of the log
 //End: tails and follows the log
+//g: go to a line, a percentage of the file or a number of lines up or down
+//t: go to the first entry at or after a time
+//F12: shows all files interleaved by time
+//Space: search, in the search box CTRL-R toggles regex, CTRL-E case sensitive, CTRL-W whole word matching and CTRL-Q a query over the columns
+//n/N: next/previous match of the last search
+//f: adds a filter to the active file showing only the entries matching the term, a term starting with ! hides the matching entries instead
+//F: removes all filters of the active file
+//e/E: next/previous entry of the chosen severity level, s: chooses the next level
+//1-9: shows/hides the entries of the severity level of the active file's severity rule with the number, 0 shows all levels
+//c: collapses every entry and its continuation lines into one row, or expands them again, the position is kept
+//x: lists the distinct stack traces by count, Enter or Mouse Left on one goes to its first entry, Esc and q close the list
+//p: lists the patterns of the messages by count, the numbers and IDs in them masked, Enter or Mouse Left on one filters on it, Esc and q close the list
+//Esc: clears the search and the highlighted matches, closes the input boxes
 func (l *LogDisplay) keybindings(g *gocui.Gui) error {
 	if err := g.SetKeybinding("", gocui.KeyCtrlC, gocui.ModNone, quit); err != nil {
 		return err
@@ -287,7 +602,34 @@ func (l *LogDisplay) keybindings(g *gocui.Gui) error {
 		return err
 	}
 
-	if err := g.SetKeybinding(detailsView, gocui.MouseRight, gocui.ModNone, hideLogEntryDetails); err != nil {
+	if err := g.SetKeybinding(detailsView, gocui.MouseRight, gocui.ModNone, l.closeDetails); err != nil {
+		return err
+	}
+
+	detailsKeys := map[interface{}]func(*gocui.Gui, *gocui.View) error{
+		gocui.KeyArrowUp:   l.scrollDetails(-1),
+		gocui.KeyArrowDown: l.scrollDetails(1),
+		gocui.KeyPgup:      l.scrollDetailsPage(-1),
+		gocui.KeyPgdn:      l.scrollDetailsPage(1),
+		gocui.KeyHome:      l.detailsHome,
+		gocui.KeyEnd:       l.detailsEnd,
+		'/':                l.detailsSearch,
+		'n':                l.nextDetailsMatch,
+		'N':                l.previousDetailsMatch,
+		gocui.KeyEsc:       l.closeDetails,
+		'q':                l.closeDetails,
+	}
+	for key, handler := range detailsKeys {
+		if err := g.SetKeybinding(detailsView, key, gocui.ModNone, handler); err != nil {
+			return err
+		}
+	}
+
+	if err := g.SetKeybinding(detailsSearchField, gocui.KeyEnter, gocui.ModNone, l.performDetailsSearch); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(detailsSearchField, gocui.KeyEsc, gocui.ModNone, l.exitDetailsSearch); err != nil {
 		return err
 	}
 
@@ -355,6 +697,10 @@ func (l *LogDisplay) keybindings(g *gocui.Gui) error {
 		return err
 	}
 
+	if err := g.SetKeybinding(mainView, gocui.KeyF12, gocui.ModNone, l.switchToMerged); err != nil {
+		return err
+	}
+
 	if err := g.SetKeybinding(mainView, gocui.KeySpace, gocui.ModNone, l.search); err != nil {
 		return err
 	}
@@ -367,6 +713,145 @@ func (l *LogDisplay) keybindings(g *gocui.Gui) error {
 		return err
 	}
 
+	if err := g.SetKeybinding(mainView, 'n', gocui.ModNone, l.nextMatch); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(mainView, 'N', gocui.ModNone, l.previousMatch); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(mainView, gocui.KeyEsc, gocui.ModNone, l.clearSearch); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(mainView, 'f', gocui.ModNone, l.filter); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(filterField, gocui.KeyEnter, gocui.ModNone, l.performFilter); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(mainView, 'F', gocui.ModNone, l.clearFilters); err != nil {
+		return err
+	}
+
+	//The keys of the levels the active file doesn't have do nothing
+	for index := 0; index < 9; index++ {
+		if err := g.SetKeybinding(mainView, rune('1' + index), gocui.ModNone, l.toggleSeverity(index)); err != nil {
+			return err
+		}
+	}
+
+	if err := g.SetKeybinding(mainView, '0', gocui.ModNone, l.showAllSeverities); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(mainView, 'e', gocui.ModNone, l.nextLevelEntry); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(mainView, 'E', gocui.ModNone, l.previousLevelEntry); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(mainView, 's', gocui.ModNone, l.cycleJumpLevel); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(mainView, 'c', gocui.ModNone, l.toggleCollapsed); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(mainView, 'x', gocui.ModNone, l.showExceptions); err != nil {
+		return err
+	}
+
+	exceptionsKeys := map[interface{}]func(*gocui.Gui, *gocui.View) error{
+		gocui.KeyArrowUp:   l.selectException(-1),
+		gocui.KeyArrowDown: l.selectException(1),
+		gocui.KeyPgup:      l.selectException(-10),
+		gocui.KeyPgdn:      l.selectException(10),
+		gocui.KeyEnter:     l.goToException,
+		gocui.MouseLeft:    l.goToException,
+		gocui.KeyEsc:       l.closeExceptions,
+		'q':                l.closeExceptions,
+	}
+	for key, handler := range exceptionsKeys {
+		if err := g.SetKeybinding(exceptionsView, key, gocui.ModNone, handler); err != nil {
+			return err
+		}
+	}
+
+	if err := g.SetKeybinding(mainView, 'p', gocui.ModNone, l.showPatterns); err != nil {
+		return err
+	}
+
+	patternsKeys := map[interface{}]func(*gocui.Gui, *gocui.View) error{
+		gocui.KeyArrowUp:   l.selectPattern(-1),
+		gocui.KeyArrowDown: l.selectPattern(1),
+		gocui.KeyPgup:      l.selectPattern(-10),
+		gocui.KeyPgdn:      l.selectPattern(10),
+		gocui.KeyEnter:     l.filterPattern,
+		gocui.MouseLeft:    l.filterPattern,
+		gocui.KeyEsc:       l.closePatterns,
+		'q':                l.closePatterns,
+	}
+	for key, handler := range patternsKeys {
+		if err := g.SetKeybinding(patternsView, key, gocui.ModNone, handler); err != nil {
+			return err
+		}
+	}
+
+	for _, field := range []string{searchField, goToField, goToTimeField, filterField} {
+		if err := g.SetKeybinding(field, gocui.KeyEsc, gocui.ModNone, l.exitSearch); err != nil {
+			return err
+		}
+	}
+
+	for _, field := range []string{searchField, filterField, detailsSearchField} {
+		if err := g.SetKeybinding(field, gocui.KeyCtrlR, gocui.ModNone, l.toggleRegex); err != nil {
+			return err
+		}
+
+		if err := g.SetKeybinding(field, gocui.KeyCtrlE, gocui.ModNone, l.toggleCaseSensitive); err != nil {
+			return err
+		}
+
+		if err := g.SetKeybinding(field, gocui.KeyCtrlW, gocui.ModNone, l.toggleWholeWord); err != nil {
+			return err
+		}
+
+		if err := g.SetKeybinding(field, gocui.KeyCtrlQ, gocui.ModNone, l.toggleQuery); err != nil {
+			return err
+		}
+	}
+
+	if err := g.SetKeybinding(mainView, 'g', gocui.ModNone, l.goTo); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(goToField, gocui.KeyEnd, gocui.ModNone, l.exitSearch); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(goToField, gocui.KeyEnter, gocui.ModNone, l.performGoTo); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(mainView, 't', gocui.ModNone, l.goToTime); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(goToTimeField, gocui.KeyEnd, gocui.ModNone, l.exitSearch); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(goToTimeField, gocui.KeyEnter, gocui.ModNone, l.performGoToTime); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -376,9 +861,9 @@ func quit(g *gocui.Gui, v *gocui.View) error {
 	return gocui.ErrQuit
 }
 
-//Shows the full details of the log entry in a popup window
-//Returns an error if the detailsView view cannot be found
-func (l LogDisplay) showLogEntryDetails(g *gocui.Gui, v *gocui.View) error {
+//Shows the whole log entry in a scrollable popup window that takes the keys until it is closed
+//Returns an error if the detailsView view cannot be created
+func (l *LogDisplay) showLogEntryDetails(g *gocui.Gui, v *gocui.View) error {
 	if _, err := g.SetCurrentView(v.Name()); err != nil {
 		return err
 	}
@@ -389,18 +874,22 @@ func (l LogDisplay) showLogEntryDetails(g *gocui.Gui, v *gocui.View) error {
 	clipboard.WriteAll(message)
 
 	maxX, maxY := g.Size()
-	if v, err := g.SetView(detailsView, 5, 5, maxX-5, maxY-5); err != nil {
-		v.Wrap = true
-		v.Editable = true
-
-		v.Title = "Line details"
-		if err != gocui.ErrUnknownView {
-			return err
-		}
-
-		fmt.Fprintln(v, message)
+	details, err := g.SetView(detailsView, 5, 5, maxX-5, maxY-5)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
 	}
-	return nil
+	details.Wrap = false
+	details.Editable = false
+	details.Title = detailsTitle
+
+	width, _ := details.Size()
+	l.setDetails(message, width)
+	l.detailsMatcher = nil
+	l.detailsMatch = -1
+	details.SetOrigin(0, 0)
+	l.renderDetails(g)
+	_, err = g.SetCurrentView(detailsView)
+	return err
 }
 
 //Hides the log entry details popup
@@ -409,7 +898,8 @@ func hideLogEntryDetails(g *gocui.Gui, v *gocui.View) error {
 	if err := g.DeleteView(detailsView); err != nil {
 		return err
 	}
-	return nil
+	_, err := g.SetCurrentView(mainView)
+	return err
 }
 
 //Navigates to the beginning of the file
@@ -440,7 +930,7 @@ func (l *LogDisplay) end(g *gocui.Gui, v *gocui.View) error {
 		if err != nil {
 			return err
 		}
-		l.currentPage = l.logReader.Tail()
+		l.tail()
 		l.rerender(g)
 		return nil
 	})
@@ -510,20 +1000,30 @@ func (l *LogDisplay) switchToFile10(g *gocui.Gui, v *gocui.View) error {
 	return nil
 }
 
+//Sets the merged view of all files to the active one
+func (l *LogDisplay) switchToMerged(g *gocui.Gui, v *gocui.View) error {
+	if len(l.logdisplayConfig.Files) > 1 {
+		l.switchToFile(g, v, l.logReader.MergedIndex())
+	}
+	return nil
+}
+
 //Switches to the log file at the specified index
 func (l *LogDisplay) switchToFile(g *gocui.Gui, v *gocui.View, index int) error {
 	l.exitSearchMode()
-	previousFileIndex := l.logFileIndex
 	l.logFileIndex = index
+	//The file might have its own severities, the jumps start over with its first level
+	if l.jumpLevel >= len(l.activeFileConfig().Severities) {
+		l.jumpLevel = 0
+	}
+	l.levelMatcher = nil
+	l.rotationNotice = ""
 	l.logReader.FileIndex = index
 
 	g.Update(func(g *gocui.Gui) error {
-		prev, _ := g.View(l.logdisplayConfig.Files[previousFileIndex].Name)
-		active, _ := g.View(l.logdisplayConfig.Files[index].Name)
-		prev.Clear()
-		fmt.Fprint(prev, l.logdisplayConfig.Files[previousFileIndex].Name)
-		active.Clear()
-		fmt.Fprint(active, colorizeActive(l.logdisplayConfig.Files[index].Name, l.logdisplayConfig))
+		l.newLines[index] = 0
+		l.logReader.MarkSeen(index)
+		l.renderFileTabs(g)
 
 		_, err := g.View(mainView)
 		if err != nil {
@@ -536,6 +1036,33 @@ func (l *LogDisplay) switchToFile(g *gocui.Gui, v *gocui.View, index int) error
 	return nil
 }
 
+//Writes the file names to their tabs, highlighting the active file and showing the number of new lines in the others
+func (l *LogDisplay) renderFileTabs(g *gocui.Gui) {
+	for index, file := range l.logdisplayConfig.Files {
+		v, err := g.View(file.Name)
+		if err != nil {
+			continue
+		}
+		v.Clear()
+		if index == l.logFileIndex {
+			fmt.Fprint(v, colorizeActive(file.Name, l.logdisplayConfig))
+		} else if l.newLines[index] > 0 {
+			fmt.Fprintf(v, "%s +%d", file.Name, l.newLines[index])
+		} else {
+			fmt.Fprint(v, file.Name)
+		}
+	}
+
+	if v, err := g.View(mergedTab); err == nil {
+		v.Clear()
+		if l.merged() {
+			fmt.Fprint(v, colorizeActive("Merged", l.logdisplayConfig))
+		} else {
+			fmt.Fprint(v, "Merged")
+		}
+	}
+}
+
 //Displays a box for entering a search term and performing search
 //Navigates to the location of the search result
 func (l *LogDisplay) search(g *gocui.Gui, v *gocui.View) error {
@@ -545,7 +1072,7 @@ func (l *LogDisplay) search(g *gocui.Gui, v *gocui.View) error {
 		v.Wrap = false
 		v.Editable = true
 
-		v.Title = "Search"
+		v.Title = searchTitle(l.searchOptions)
 		if err != gocui.ErrUnknownView {
 			return err
 		}
@@ -555,6 +1082,60 @@ func (l *LogDisplay) search(g *gocui.Gui, v *gocui.View) error {
 	return nil
 }
 
+//Returns the title of the search box showing the active search modes and the keys toggling them
+func searchTitle(options logreader.SearchOptions) string {
+	title := "Search"
+	if modes := options.String(); modes != "" {
+		title = title + " (" + modes + ")"
+	}
+	return title + " - ^R regex, ^E case, ^W word, ^Q query"
+}
+
+//Returns the title of the filter box showing the active search modes and the keys toggling them
+func filterTitle(options logreader.SearchOptions) string {
+	title := "Filter, !term hides"
+	if modes := options.String(); modes != "" {
+		title = title + " (" + modes + ")"
+	}
+	return title + " - ^R regex, ^E case, ^W word, ^Q query"
+}
+
+//Returns the title of the search or filter box with the active search modes
+func optionsTitle(field string, options logreader.SearchOptions) string {
+	if field == filterField {
+		return filterTitle(options)
+	}
+	return searchTitle(options)
+}
+
+//Toggles between matching the search term as a regular expression and as plain text
+func (l *LogDisplay) toggleRegex(g *gocui.Gui, v *gocui.View) error {
+	l.searchOptions.Regex = !l.searchOptions.Regex
+	v.Title = optionsTitle(v.Name(), l.searchOptions)
+	return nil
+}
+
+//Toggles between matching the search term as text and as a query over the columns such as Severity=ERROR AND NOT Thread~"-[12]$"
+func (l *LogDisplay) toggleQuery(g *gocui.Gui, v *gocui.View) error {
+	l.searchOptions.Query = !l.searchOptions.Query
+	v.Title = optionsTitle(v.Name(), l.searchOptions)
+	return nil
+}
+
+//Toggles case sensitive matching of the search term
+func (l *LogDisplay) toggleCaseSensitive(g *gocui.Gui, v *gocui.View) error {
+	l.searchOptions.CaseSensitive = !l.searchOptions.CaseSensitive
+	v.Title = optionsTitle(v.Name(), l.searchOptions)
+	return nil
+}
+
+//Toggles matching the search term as whole words only
+func (l *LogDisplay) toggleWholeWord(g *gocui.Gui, v *gocui.View) error {
+	l.searchOptions.WholeWord = !l.searchOptions.WholeWord
+	v.Title = optionsTitle(v.Name(), l.searchOptions)
+	return nil
+}
+
 //Exits the search mode
 //Returns an error if the searchView view cannot be found
 func (l LogDisplay) exitSearch(g *gocui.Gui, v *gocui.View) error {
@@ -567,18 +1148,333 @@ func (l LogDisplay) exitSearch(g *gocui.Gui, v *gocui.View) error {
 }
 
 //Sets the search mode flag to true and calls the search func on logreader
-func (l LogDisplay) performSearch(g *gocui.Gui, v *gocui.View) error {
+//Shows an invalid regular expression or the lack of matches in the title of the search box
+func (l *LogDisplay) performSearch(g *gocui.Gui, v *gocui.View) error {
+	searchTerm, _ := v.Line(0)
+	matcher, err := logreader.NewMatcher(searchTerm, l.searchOptions)
+	if err == nil {
+		err = l.logReader.CheckMatcher(matcher)
+	}
+	if err != nil {
+		v.Title = err.Error()
+		return nil
+	}
+
+	l.searchMatcher = matcher
+	l.showMatch(g, l.logReader.SearchWith)
+	if l.searchResultLocation == -1 {
+		v.Title = "No match - " + searchTitle(l.searchOptions)
+	} else {
+		v.Title = searchTitle(l.searchOptions)
+	}
+
+	return nil
+}
+
+//Navigates to the next match of the last search
+func (l *LogDisplay) nextMatch(g *gocui.Gui, v *gocui.View) error {
+	if l.searchMatcher != nil {
+		l.showMatch(g, l.logReader.SearchWith)
+	}
+	return nil
+}
+
+//Navigates to the previous match of the last search
+func (l *LogDisplay) previousMatch(g *gocui.Gui, v *gocui.View) error {
+	if l.searchMatcher != nil {
+		l.showMatch(g, l.logReader.SearchBackward)
+	}
+	return nil
+}
+
+//Forgets the last search, removing the highlighted matches
+func (l *LogDisplay) clearSearch(g *gocui.Gui, v *gocui.View) error {
+	l.searchMatcher = nil
+	l.exitSearchMode()
+	l.rerender(g)
+	return nil
+}
+
+//Searches from the current match in the direction of the search func and shows the page with the match found
+func (l *LogDisplay) showMatch(g *gocui.Gui, search func(logreader.Matcher, int) (*[][]string, int)) {
 	l.searchOn = &[]bool{true}[0]
 	l.tailOn[l.logFileIndex] = &[]bool{false}[0]
-	searchTerm, _ := v.Line(0)
-	l.currentPage, l.searchResultLocation = l.logReader.Search(searchTerm, l.searchResultLocation)
+	l.currentPage, l.searchResultLocation = search(l.searchMatcher, l.searchResultLocation)
 	details, _ := g.View(mainView)
 	details.Clear()
 	l.rerender(g)
+}
+
+//Displays a box for entering the term of a new filter, stacked on top of the active ones
+func (l *LogDisplay) filter(g *gocui.Gui, v *gocui.View) error {
+	maxX, maxY := g.Size()
+	if v, err := g.SetView(filterField, 5, maxY-3, maxX-40, maxY-1); err != nil {
+		v.Wrap = false
+		v.Editable = true
+
+		v.Title = filterTitle(l.searchOptions)
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	g.SetCurrentView(filterField)
+	return nil
+}
+
+//Adds the filter typed in the filter box and closes it
+//Keeps the box open with the error in its title if the term is empty or an invalid regular expression
+func (l *LogDisplay) performFilter(g *gocui.Gui, v *gocui.View) error {
+	input, _ := v.Line(0)
+	filter, err := parseFilter(input, l.searchOptions)
+	if err == nil {
+		err = l.logReader.CheckMatcher(filter.Matcher)
+	}
+	if err != nil {
+		v.Title = err.Error()
+		return nil
+	}
+
+	l.filters[l.logFileIndex] = append(l.filters[l.logFileIndex], filter)
+	l.applyFilters(g)
+
+	return l.exitSearch(g, v)
+}
+
+//Collapses every entry and its continuation lines into one row or expands them again, keeping the position in the log
+//A collapsed entry is expanded in the details view
+func (l *LogDisplay) toggleCollapsed(g *gocui.Gui, v *gocui.View) error {
+	l.logReader.SetCollapsed(!l.logReader.Collapsed())
+	l.exitSearchMode()
+	if *l.tailOn[l.logFileIndex] {
+		l.tail()
+	} else {
+		l.currentPage = l.logReader.Refresh()
+	}
+	l.rerender(g)
+	return nil
+}
+
+//Removes all filters of the active file typed in the filter box, the hidden severity levels stay hidden
+func (l *LogDisplay) clearFilters(g *gocui.Gui, v *gocui.View) error {
+	if len(l.filters[l.logFileIndex]) > 0 {
+		l.filters[l.logFileIndex] = nil
+		l.applyFilters(g)
+	}
+	return nil
+}
+
+//Returns the handler showing/hiding the entries of the severity level at the index in the active file
+func (l *LogDisplay) toggleSeverity(index int) func(g *gocui.Gui, v *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		hidden := l.hiddenSeverities[l.logFileIndex]
+		if index >= len(hidden) {
+			return nil
+		}
+		hidden[index] = !hidden[index]
+		l.applyFilters(g)
+		return nil
+	}
+}
+
+//Shows the entries of every severity level in the active file
+func (l *LogDisplay) showAllSeverities(g *gocui.Gui, v *gocui.View) error {
+	for _, hidden := range l.hiddenSeverities[l.logFileIndex] {
+		if hidden {
+			l.hiddenSeverities[l.logFileIndex] = make([]bool, len(l.hiddenSeverities[l.logFileIndex]))
+			l.applyFilters(g)
+			return nil
+		}
+	}
+	return nil
+}
+
+//Navigates to the next entry of the chosen severity level
+func (l *LogDisplay) nextLevelEntry(g *gocui.Gui, v *gocui.View) error {
+	l.jumpToLevel(g, l.logReader.SearchWith)
+	return nil
+}
+
+//Navigates to the previous entry of the chosen severity level
+func (l *LogDisplay) previousLevelEntry(g *gocui.Gui, v *gocui.View) error {
+	l.jumpToLevel(g, l.logReader.SearchBackward)
+	return nil
+}
+
+//Chooses the next severity level of the active file to jump to with e/E, after the last one comes the first one
+func (l *LogDisplay) cycleJumpLevel(g *gocui.Gui, v *gocui.View) error {
+	if levels := len(l.activeFileConfig().Severities); levels > 0 {
+		l.jumpLevel = (l.jumpLevel + 1) % levels
+		l.levelMatcher = nil
+		l.rerender(g)
+	}
+	return nil
+}
+
+//Searches from the focused entry in the direction of the search func for an entry of the chosen level and shows the page with it
+//From the first jump on the entries of the level above and below the page are counted
+func (l *LogDisplay) jumpToLevel(g *gocui.Gui, search func(logreader.Matcher, int) (*[][]string, int)) {
+	if len(l.activeFileConfig().Severities) == 0 {
+		return
+	}
+	l.tailOn[l.logFileIndex] = &[]bool{false}[0]
+	l.searchOn = &[]bool{false}[0]
+	l.levelJumps = true
+	l.currentPage, l.searchResultLocation = search(l.levelEntries(), l.searchResultLocation)
+	l.rerender(g)
+}
+
+//Returns the matcher of the entries of the chosen severity level of the active file, the same one until the level or the file changes so the reader keeps its matches
+func (l *LogDisplay) levelEntries() logreader.Matcher {
+	if l.levelMatcher == nil {
+		severities := l.activeFileConfig().Severities
+		only := make([]bool, len(severities))
+		only[l.jumpLevel] = true
+		l.levelMatcher = logreader.EntriesOnly(&severityFilter{severities, only})
+	}
+	return l.levelMatcher
+}
+
+//Returns the filters of the active file typed in the filter box followed by the filter hiding its severity levels toggled off
+func (l *LogDisplay) activeFilters() []logreader.Filter {
+	filters := append([]logreader.Filter{}, l.filters[l.logFileIndex]...)
+	for _, hidden := range l.hiddenSeverities[l.logFileIndex] {
+		if hidden {
+			severities := severityFilter{l.activeFileConfig().Severities, append([]bool{}, l.hiddenSeverities[l.logFileIndex]...)}
+			return append(filters, logreader.Filter{Matcher: severities, Exclude: true})
+		}
+	}
+	return filters
+}
+
+//Sets the filters of the active file in the reader and shows the filtered view from the end when tailing, from the beginning otherwise
+//The page fills as the reader copies the filtered entries in the background
+//The position of the last search result doesn't exist in the new view, the next search starts over
+func (l *LogDisplay) applyFilters(g *gocui.Gui) {
+	l.logReader.SetFilters(l.activeFilters())
+	l.exitSearchMode()
+	if *l.tailOn[l.logFileIndex] {
+		l.tail()
+	} else {
+		l.currentPage = l.logReader.Head()
+	}
+	l.rerender(g)
+}
+
+//Parses the term typed in the filter box, a leading ! makes the filter hide the matching entries
+//Returns an error if the term is empty or isn't a valid regular expression in regex mode
+func parseFilter(input string, options logreader.SearchOptions) (logreader.Filter, error) {
+	term := strings.TrimSpace(input)
+	exclude := strings.HasPrefix(term, "!")
+	term = strings.TrimPrefix(term, "!")
+	if term == "" {
+		return logreader.Filter{}, errors.New("Enter a term to show, !term to hide")
+	}
+
+	matcher, err := logreader.NewMatcher(term, options)
+	if err != nil {
+		return logreader.Filter{}, err
+	}
+
+	return logreader.Filter{Matcher: matcher, Exclude: exclude}, nil
+}
+
+//Displays a box for entering a line number, a percentage or a relative number of lines to navigate to
+func (l *LogDisplay) goTo(g *gocui.Gui, v *gocui.View) error {
+	maxX, maxY := g.Size()
+	if v, err := g.SetView(goToField, 5, maxY-3, maxX-40, maxY-1); err != nil {
+		v.Wrap = false
+		v.Editable = true
+
+		v.Title = "Go to line, N% or +/-N lines"
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	g.SetCurrentView(goToField)
+	return nil
+}
+
+//Navigates to the position typed in the go to box and closes it
+//Keeps the box open with the error in its title if the input cannot be parsed
+func (l *LogDisplay) performGoTo(g *gocui.Gui, v *gocui.View) error {
+	input, _ := v.Line(0)
+	target, err := parseGoToTarget(input)
+	if err != nil {
+		v.Title = err.Error()
+		return nil
+	}
+
+	l.tailOn[l.logFileIndex] = &[]bool{false}[0]
+	l.exitSearchMode()
+	switch target.kind {
+	case goToPercent:
+		l.currentPage = l.logReader.SeekPercent(target.value)
+	case goToRelative:
+		l.currentPage = l.logReader.SeekRelative(target.value)
+	default:
+		l.currentPage = l.logReader.SeekLine(target.value)
+	}
+	l.rerender(g)
+
+	return l.exitSearch(g, v)
+}
 
+//Displays a box for entering the time to navigate to, either a full timestamp or a time of day
+func (l *LogDisplay) goToTime(g *gocui.Gui, v *gocui.View) error {
+	maxX, maxY := g.Size()
+	if v, err := g.SetView(goToTimeField, 5, maxY-3, maxX-40, maxY-1); err != nil {
+		v.Wrap = false
+		v.Editable = true
+
+		v.Title = "Go to time"
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	g.SetCurrentView(goToTimeField)
 	return nil
 }
 
+//Navigates to the first entry at or after the time typed in the go to time box and closes it
+//Keeps the box open with the error in its title if the time cannot be parsed
+func (l *LogDisplay) performGoToTime(g *gocui.Gui, v *gocui.View) error {
+	input, _ := v.Line(0)
+	target, err := l.logReader.ParseTime(input)
+	if err != nil {
+		v.Title = err.Error()
+		return nil
+	}
+
+	l.tailOn[l.logFileIndex] = &[]bool{false}[0]
+	l.exitSearchMode()
+	l.currentPage = l.logReader.SeekTime(target)
+	l.rerender(g)
+
+	return l.exitSearch(g, v)
+}
+
+//Parses the text typed in the go to box
+//Returns an error if the text isn't a line number, a percentage or a signed number of lines
+func parseGoToTarget(input string) (goToTarget, error) {
+	input = strings.TrimSpace(input)
+	target := goToTarget{kind: goToLine}
+	switch {
+	case strings.HasSuffix(input, "%"):
+		target.kind = goToPercent
+		input = strings.TrimSuffix(input, "%")
+	case strings.HasPrefix(input, "+") || strings.HasPrefix(input, "-"):
+		target.kind = goToRelative
+	}
+
+	value, err := strconv.Atoi(input)
+	if err != nil || (target.kind != goToRelative && value < 0) {
+		return target, errors.New("Enter a line, N% or +/-N lines")
+	}
+	target.value = value
+
+	return target, nil
+}
+
 //Navigates one page down where the page size equals the "capacity" configuration
 //Returns an error if the main view cannot be found
 func (l *LogDisplay) pageDown(g *gocui.Gui, v *gocui.View) error {
@@ -662,6 +1558,46 @@ func (l *LogDisplay) rerender(g *gocui.Gui){
 	progressWidget.Clear()
 	fmt.Fprintf(progressWidget, "%d%%", l.logReader.Progress())
 
+	counterWidget, _ := g.View(matchCounter)
+	counterWidget.Clear()
+	//The matches are counted in the background, the page is rendered again once they are
+	if *l.searchOn && l.searchResultLocation != -1 {
+		if index, total, ready := l.logReader.MatchPosition(l.searchMatcher, l.searchResultLocation); ready {
+			fmt.Fprintf(counterWidget, "%d of %d", index, total)
+		} else {
+			fmt.Fprint(counterWidget, countingText)
+		}
+	} else if *l.searchOn {
+		fmt.Fprint(counterWidget, "none")
+	}
+
+	filterWidget, _ := g.View(filterIndicator)
+	filterWidget.Clear()
+	if filters := len(l.filters[l.logFileIndex]); filters > 0 {
+		fmt.Fprintf(filterWidget, " \033[3%d;%d;1m%d active\033[0m", 3, 4, filters)
+	} else {
+		fmt.Fprint(filterWidget, "none")
+	}
+
+	jumpWidget, _ := g.View(jumpIndicator)
+	jumpWidget.Clear()
+	if severities := l.activeFileConfig().Severities; len(severities) > 0 {
+		rule := severities[l.jumpLevel]
+		fmt.Fprint(jumpWidget, fmt.Sprint(fmt.Sprintf("\033[3%d;%d;1m", rule.Colors...), severityLabel(rule), colorReset))
+		if l.levelJumps {
+			if above, below, ready := l.logReader.MatchesAround(l.levelEntries()); ready {
+				fmt.Fprintf(jumpWidget, " ↑%d ↓%d", above, below)
+			} else {
+				fmt.Fprint(jumpWidget, " "+countingText)
+			}
+		}
+	}
+
+	levelsWidget, _ := g.View(levelsIndicator)
+	levelsWidget.Clear()
+	levelsWidget.Title = levelsTitle(len(l.activeFileConfig().Severities))
+	fmt.Fprint(levelsWidget, levelsText(l.activeFileConfig().Severities, l.hiddenSeverities[l.logFileIndex]))
+
 	tailWidget, _ := g.View(tailIndicator)
 	tailWidget.Clear()
 	if *l.tailOn[l.logFileIndex] {
@@ -670,6 +1606,24 @@ func (l *LogDisplay) rerender(g *gocui.Gui){
 		fmt.Fprintf(tailWidget, " \033[3%d;%d;1m%s\033[0m", 1, 4, "OFF")
 	}
 
+	l.renderRotationNotice(g)
+}
+
+//Shows a marker over the top right corner of the main view for a while after the file was rotated or truncated
+func (l *LogDisplay) renderRotationNotice(g *gocui.Gui) {
+	if l.rotationNotice == "" || time.Since(l.rotationNoticeTime) > rotationNoticeDuration {
+		l.rotationNotice = ""
+		g.DeleteView(rotationIndicator)
+		return
+	}
+
+	maxX, _ := g.Size()
+	v, err := g.SetView(rotationIndicator, maxX-32, 0, maxX-1, 2)
+	if err != nil && err != gocui.ErrUnknownView {
+		return
+	}
+	v.Clear()
+	fmt.Fprintf(v, " \033[3%d;%d;1m%s at %s\033[0m", 1, 4, l.rotationNotice, l.rotationNoticeTime.Format("15:04:05"))
 }
 
 func (l *LogDisplay) exitSearchMode() {
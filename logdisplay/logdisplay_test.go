@@ -104,3 +104,70 @@ func TestLogDisplay_writeHeader(t *testing.T) {
 		t.Errorf(`Output Log: Expected %s to match %s`, actual.String(), expectedRegexp)
 	}
 }
+
+func TestLogDisplay_activeFileConfig_perFileSeverities(t *testing.T) {
+	logReader := logreader.NewLogReader(logreaderConfig("", []int{10, 10, 10}))
+	config := logdisplayConfig()
+	config.Files = []LogFile{
+		{LogFile: "app.log", Name: "app"},
+		{LogFile: "access.log", Name: "access", Severities: []Severity{{Severity: ` 5\d\d `, Colors: []interface{}{1, 1}}}},
+	}
+	logdisplay := NewLogDisplay(&logReader, config)
+
+	if actual := logdisplay.activeFileConfig().Severities; !reflect.DeepEqual(actual, config.Severities) {
+		t.Errorf("Expected the top level severities, got %v", actual)
+	}
+
+	logdisplay.logFileIndex = 1
+	if actual := logdisplay.activeFileConfig().Severities; !reflect.DeepEqual(actual, config.Files[1].Severities) {
+		t.Errorf("Expected the severities of access.log, got %v", actual)
+	}
+}
+
+func TestLogDisplay_parseGoToTarget(t *testing.T) {
+	valid := map[string]goToTarget{
+		"12345": {goToLine, 12345},
+		" 50% ": {goToPercent, 50},
+		"+200":  {goToRelative, 200},
+		"-200":  {goToRelative, -200},
+	}
+	for input, expected := range valid {
+		if actual, err := parseGoToTarget(input); err != nil || actual != expected {
+			t.Errorf("Expected %q to be parsed as %v, got %v (%v)", input, expected, actual, err)
+		}
+	}
+
+	for _, input := range []string{"", "abc", "-5%", "12 lines"} {
+		if _, err := parseGoToTarget(input); err == nil {
+			t.Errorf("Expected %q to be rejected", input)
+		}
+	}
+}
+
+func TestLogDisplay_searchTitle(t *testing.T) {
+	if actual := searchTitle(logreader.SearchOptions{}); actual != "Search - ^R regex, ^E case, ^W word, ^Q query" {
+		t.Errorf("Expected no modes in the default title, got %q", actual)
+	}
+	expected := "Search (regex, whole word) - ^R regex, ^E case, ^W word, ^Q query"
+	if actual := searchTitle(logreader.SearchOptions{Regex: true, WholeWord: true}); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+func TestLogDisplay_parseFilter(t *testing.T) {
+	filter, err := parseFilter(" !Thread-3 ", logreader.SearchOptions{})
+	if err != nil || !filter.Exclude || !filter.Matcher.Match("11/11/2010~thread-3~com.test") {
+		t.Errorf("Expected an exclude filter matching Thread-3, got %v (%v)", filter, err)
+	}
+
+	filter, err = parseFilter("ERROR|WARN", logreader.SearchOptions{Regex: true})
+	if err != nil || filter.Exclude || !filter.Matcher.Match("WARN disk almost full") {
+		t.Errorf("Expected an include filter matching WARN, got %v (%v)", filter, err)
+	}
+
+	for _, input := range []string{"", " ! ", "("} {
+		if _, err := parseFilter(input, logreader.SearchOptions{Regex: true}); err == nil {
+			t.Errorf("Expected %q to be rejected", input)
+		}
+	}
+}
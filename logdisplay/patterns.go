@@ -0,0 +1,140 @@
+package logdisplay
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jroimartin/gocui"
+	"github.com/oskanaan/golog/logreader"
+)
+
+const patternsView = "patterns"
+
+//Title of the patterns view listing its keys
+const patternsTitle = "Patterns of %s by count - arrows select, Enter or click filters on one, Esc close"
+
+//Header of the column mined for patterns if the configuration doesn't set one and the file has it, the last column otherwise
+const defaultPatternColumn = "Message"
+
+//Returns the rows of the patterns view, the count and the template with its first message
+func patternRows(patterns []logreader.Pattern) []string {
+	if len(patterns) == 0 {
+		return []string{"No messages found"}
+	}
+
+	rows := make([]string, len(patterns))
+	for index, pattern := range patterns {
+		rows[index] = fmt.Sprintf("%7d  %s    e.g. %s", pattern.Count, pattern.Template, pattern.Example)
+	}
+	return rows
+}
+
+//Returns the header of the column mined for patterns in the active file, the configured one or Message if the file has it, otherwise its last column
+//The lines column of the collapsed view counts continuation lines, it's never chosen
+func patternColumn(configured string, headers []string) string {
+	if len(headers) > 0 && headers[len(headers)-1] == logreader.LinesHeader {
+		headers = headers[:len(headers)-1]
+	}
+	if len(headers) == 0 {
+		return configured
+	}
+	for _, candidate := range []string{configured, defaultPatternColumn} {
+		for _, header := range headers {
+			if candidate != "" && strings.EqualFold(header, candidate) {
+				return header
+			}
+		}
+	}
+	return headers[len(headers)-1]
+}
+
+//Clusters the messages of the active file into patterns in the background and lists them in a popup window that takes the keys until it is closed
+//The popup shows the patterns are being mined until they are read, the error is listed instead if the file has no column with the header
+func (l *LogDisplay) showPatterns(g *gocui.Gui, v *gocui.View) error {
+	l.patternsColumn = patternColumn(l.logdisplayConfig.PatternColumn, l.logReader.GetHeaders())
+	l.patterns = nil
+	l.listScans++
+	scan := l.listScans
+
+	maxX, maxY := g.Size()
+	view, err := g.SetView(patternsView, 5, 5, maxX-5, maxY-5)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	view.Wrap = false
+	view.Editable = false
+	view.Highlight = true
+	view.SelBgColor = gocui.ColorWhite
+	view.SelFgColor = gocui.ColorBlack
+	view.Title = fmt.Sprintf(patternsTitle+" (%s)", l.patternsColumn, countingText)
+	view.Clear()
+	view.SetOrigin(0, 0)
+	view.SetCursor(0, 0)
+
+	err = l.logReader.Patterns(l.patternsColumn, func(patterns []logreader.Pattern) {
+		g.Update(func(g *gocui.Gui) error {
+			//The popup was closed or opened again while the patterns were mined
+			view, err := g.View(patternsView)
+			if scan != l.listScans || err != nil {
+				return nil
+			}
+			l.patterns = patterns
+			view.Title = fmt.Sprintf(patternsTitle+" (%d)", l.patternsColumn, len(patterns))
+			view.Clear()
+			for _, row := range patternRows(patterns) {
+				fmt.Fprintln(view, row)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		view.Title = fmt.Sprintf(patternsTitle+" (0)", l.patternsColumn)
+		fmt.Fprintln(view, err.Error())
+	} else {
+		fmt.Fprintln(view, "Mining the patterns "+countingText)
+	}
+	_, err = g.SetCurrentView(patternsView)
+	return err
+}
+
+//Returns the handler moving the selection in the patterns view by the number of rows, negative numbers move up
+func (l *LogDisplay) selectPattern(rows int) func(g *gocui.Gui, v *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		moveSelection(v, rows, len(l.patterns))
+		return nil
+	}
+}
+
+//Closes the patterns view and adds a filter to the active file showing only the entries of the selected pattern, it's removed with the other filters of the file
+func (l *LogDisplay) filterPattern(g *gocui.Gui, v *gocui.View) error {
+	selected := selectedRow(v)
+	if selected >= len(l.patterns) {
+		return nil
+	}
+
+	matcher, err := logreader.NewMatcher(l.patterns[selected].Query(l.patternsColumn), logreader.SearchOptions{Query: true})
+	if err == nil {
+		err = l.logReader.CheckMatcher(matcher)
+	}
+	if err != nil {
+		v.Title = err.Error()
+		return nil
+	}
+	if err := l.closePatterns(g, v); err != nil {
+		return err
+	}
+	l.filters[l.logFileIndex] = append(l.filters[l.logFileIndex], logreader.Filter{Matcher: matcher})
+	l.applyFilters(g)
+	return nil
+}
+
+//Closes the patterns view, the keys go to the log again
+func (l *LogDisplay) closePatterns(g *gocui.Gui, v *gocui.View) error {
+	l.patterns = nil
+	l.listScans++
+	if err := g.DeleteView(patternsView); err != nil {
+		return err
+	}
+	_, err := g.SetCurrentView(mainView)
+	return err
+}
@@ -0,0 +1,39 @@
+package logdisplay
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/oskanaan/golog/logreader"
+)
+
+func TestPatterns_patternRows(t *testing.T) {
+	patterns := []logreader.Pattern{{Template: "user <*> logged in", Count: 1200, Example: "user 42 logged in"}}
+	expected := []string{"   1200  user <*> logged in    e.g. user 42 logged in"}
+	if actual := patternRows(patterns); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+func TestPatterns_patternColumn(t *testing.T) {
+	cases := []struct {
+		configured string
+		headers    []string
+		expected   string
+	}{
+		{"Thread", []string{"Date", "Thread", "Message"}, "Thread"},
+		{"thread", []string{"Date", "Thread", "Message"}, "Thread"},
+		//A file without the configured column falls back to its own columns
+		{"Thread", []string{"Host", "Request", "Message"}, "Message"},
+		{"Thread", []string{"Host", "Request"}, "Request"},
+		{"", []string{"Date", "Message", "Extra"}, "Message"},
+		{"", []string{"Date", "Thread", "Package"}, "Package"},
+		//The lines column of the collapsed view is skipped
+		{"", []string{"Date", "Thread", "Package", logreader.LinesHeader}, "Package"},
+	}
+	for _, c := range cases {
+		if actual := patternColumn(c.configured, c.headers); actual != c.expected {
+			t.Errorf("Expected %q for %q and %v, got %q", c.expected, c.configured, c.headers, actual)
+		}
+	}
+}
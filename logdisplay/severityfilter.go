@@ -0,0 +1,82 @@
+package logdisplay
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//Hides the entries of the severity levels toggled off
+//An entry has the level of the first severity rule matching it, the same rule that colors it, entries matching none have the level of the last rule
+//Filters match an entry together with its continuation lines, only its first line gives its level
+type severityFilter struct {
+	rules  []Severity
+	hidden []bool
+}
+
+//Returns the index of the rule giving the entry its level
+func (f severityFilter) level(entry string, columns rowColumns) int {
+	for index, rule := range f.rules {
+		if severityRuleMatch(rule, entry, columns) {
+			return index
+		}
+	}
+	return len(f.rules) - 1
+}
+
+//Returns true if the level of the line is hidden, rules with a query only match through MatchColumns
+func (f severityFilter) Match(line string) bool {
+	return f.hidden[f.level(firstLine(line), rowColumns{})]
+}
+
+//The levels aren't highlighted, they are colored already
+func (f severityFilter) FindAll(text string) [][]int {
+	return nil
+}
+
+//Returns true if the level of the line, split into the columns named by the headers, is hidden
+func (f severityFilter) MatchColumns(headers []string, line string, columns []string) bool {
+	return f.hidden[f.level(firstLine(line), rowColumns{headers, columns})]
+}
+
+//Returns the first line of the text of an entry
+func firstLine(text string) string {
+	return strings.SplitN(text, "\n", 2)[0]
+}
+
+//Returns the name of the severity rule, or its regex without the word boundaries, or its query
+func severityLabel(rule Severity) string {
+	switch {
+	case rule.Name != "":
+		return rule.Name
+	case rule.Query != "":
+		return rule.Query
+	}
+	return strings.TrimSpace(strings.Replace(rule.Severity, `\b`, "", -1))
+}
+
+//Returns the title of the levels widget naming the keys of the levels, only the first nine levels have a key
+func levelsTitle(levels int) string {
+	if levels > 9 {
+		levels = 9
+	}
+	return "Levels 1-" + strconv.Itoa(levels)
+}
+
+//Returns the text of the levels widget, the labels of the shown levels in their colors
+func levelsText(rules []Severity, hidden []bool) string {
+	var shown []string
+	for index, rule := range rules {
+		if !hidden[index] {
+			shown = append(shown, fmt.Sprint(fmt.Sprintf("\033[3%d;%d;1m", rule.Colors...), severityLabel(rule), colorReset))
+		}
+	}
+
+	switch len(shown) {
+	case len(rules):
+		return "all"
+	case 0:
+		return "none"
+	}
+	return strings.Join(shown, " ")
+}
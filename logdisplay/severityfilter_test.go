@@ -0,0 +1,107 @@
+package logdisplay
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/oskanaan/golog/logreader"
+)
+
+func TestSeverityFilter_level(t *testing.T) {
+	filter := severityFilter{logdisplayConfig().Severities, []bool{false, true, false, false, true}}
+	cases := map[string]bool{
+		"11/11/2010~Thread-1~ERROR~disk full":      false,
+		"11/11/2010~Thread-1~WARN~disk almost full": true,
+		//The ERROR rule comes first, the entry is an error even though it mentions WARN
+		"11/11/2010~Thread-1~ERROR~WARN ignored": false,
+		"11/11/2010~Thread-1~INFO~started":       false,
+		//Entries matching no rule have the level of the last rule
+		"11/11/2010~Thread-1~started": true,
+	}
+	for line, hidden := range cases {
+		if actual := filter.Match(line); actual != hidden {
+			t.Errorf("Expected %q to be hidden %v, got %v", line, hidden, actual)
+		}
+	}
+}
+
+func TestSeverityFilter_MatchColumns_queryRule(t *testing.T) {
+	config := logdisplayConfig()
+	config.Severities = []Severity{
+		{Query: "Severity=ERROR", Colors: []interface{}{1, 1}},
+		{Severity: `\bWARN\b`, Colors: []interface{}{3, 1}},
+	}
+	config.Validate()
+	filter := severityFilter{config.Severities, []bool{true, false}}
+	headers := []string{"Thread", "Severity", "Message"}
+
+	if !filter.MatchColumns(headers, "", []string{"Thread-1", "ERROR", "disk full"}) {
+		t.Errorf("Expected the error to be hidden")
+	}
+	if filter.MatchColumns(headers, "", []string{"Thread-1", "WARN", "ERROR in the message"}) {
+		t.Errorf("Expected the warning to be shown")
+	}
+}
+
+func TestSeverityFilter_levelsText(t *testing.T) {
+	rules := []Severity{
+		{Name: "Errors", Severity: `\bERROR\b`, Colors: []interface{}{1, 1}},
+		{Severity: `\bWARN\b`, Colors: []interface{}{3, 1}},
+	}
+	if actual := levelsText(rules, []bool{false, false}); actual != "all" {
+		t.Errorf("Expected all, got %q", actual)
+	}
+	if actual := levelsText(rules, []bool{true, true}); actual != "none" {
+		t.Errorf("Expected none, got %q", actual)
+	}
+	expected := fmt.Sprint(fmt.Sprintf("\033[3%d;%d;1m", 3, 1), "WARN", colorReset)
+	if actual := levelsText(rules, []bool{true, false}); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+	if actual := severityLabel(rules[0]); actual != "Errors" {
+		t.Errorf("Expected the name as the label, got %q", actual)
+	}
+}
+
+func TestLogDisplay_levelEntries(t *testing.T) {
+	l := LogDisplay{logdisplayConfig: logdisplayConfig()}
+	l.jumpLevel = 1
+	matcher := l.levelEntries()
+	if !matcher.Match("11/11/2010~Thread-1~WARN~disk almost full") || matcher.Match("11/11/2010~Thread-1~ERROR~WARN ignored") {
+		t.Errorf("Expected only the entries of the WARN level to match")
+	}
+	if l.levelEntries() != matcher {
+		t.Errorf("Expected the same matcher until the level changes")
+	}
+}
+
+func TestLogDisplay_activeFilters_perFileLevels(t *testing.T) {
+	logReader := logreader.NewLogReader(logreaderConfig("", []int{10, 10, 10}))
+	config := logdisplayConfig()
+	config.Files = []LogFile{
+		{LogFile: "app.log", Name: "app"},
+		{LogFile: "access.log", Name: "access", Severities: []Severity{{Severity: `" 5\d\d `, Colors: []interface{}{1, 1}}, {Severity: `.`, Colors: []interface{}{2, 1}}}},
+	}
+	config.Validate()
+	l := NewLogDisplay(&logReader, config)
+	if levels := len(l.hiddenSeverities[1]); levels != 2 {
+		t.Fatalf("Expected the levels of the access.log rules, got %d", levels)
+	}
+
+	l.logFileIndex = 1
+	l.hiddenSeverities[1][0] = true
+	filters := l.activeFilters()
+	if len(filters) != 1 || !filters[0].Exclude || !filters[0].Matcher.Match(`"GET /" 503 12`) || filters[0].Matcher.Match(`"GET /" 200 12`) {
+		t.Errorf("Expected the server errors of access.log to be hidden, got %v", filters)
+	}
+
+	l.logFileIndex = 0
+	if filters := l.activeFilters(); len(filters) != 0 {
+		t.Errorf("Expected app.log to keep showing every level, got %v", filters)
+	}
+
+	l.logFileIndex = 1
+	if matcher := l.levelEntries(); !matcher.Match(`"GET /" 503 12`) {
+		t.Errorf("Expected the jumps to go to the server errors of access.log")
+	}
+}
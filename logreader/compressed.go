@@ -0,0 +1,230 @@
+package logreader
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+//Opens a decompressing reader over a compressed stream
+type decompressor func(r io.Reader) (io.ReadCloser, error)
+
+//A supported compression format, identified by the magic number at the start of the file
+type compression struct {
+	magic      []byte
+	decompress decompressor
+}
+
+var compressions = []compression{
+	{[]byte{0x1f, 0x8b}, func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	}},
+	{[]byte("BZh"), func(r io.Reader) (io.ReadCloser, error) {
+		return ioutil.NopCloser(bzip2.NewReader(r)), nil
+	}},
+	{[]byte{0x28, 0xb5, 0x2f, 0xfd}, func(r io.Reader) (io.ReadCloser, error) {
+		decoder, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	}},
+}
+
+//A decompressed copy of a compressed log file, written in the background
+type decompressedFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+//Keeps a decompressed copy of each compressed log file in the temp directory so it can be seeked like a plain file
+//The copies are reused until the compressed file changes
+type decompressionCache struct {
+	mutex sync.Mutex
+	files map[string]decompressedFile
+	grown func()
+}
+
+//Returns a cache calling grown as the copies are written, at most once per updateInterval and once each copy is complete
+func newDecompressionCache(grown func()) *decompressionCache {
+	return &decompressionCache{files: make(map[string]decompressedFile), grown: grown}
+}
+
+//Opens the log file, compressed files are transparently replaced by their decompressed copy
+//The copy is decompressed in the background, it is opened as far as it is written
+//Returns an error if the file cannot be opened or decompressed
+func (c *decompressionCache) open(path string) (*os.File, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	decompress := detectCompression(file)
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if decompress == nil {
+		return file, nil
+	}
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	cached, found := c.files[path]
+	if found && cached.size == fileInfo.Size() && cached.modTime.Equal(fileInfo.ModTime()) {
+		file.Close()
+		return os.Open(cached.path)
+	}
+	if found {
+		cached.remove()
+		delete(c.files, path)
+	}
+	cached, err = c.startCopy(file, decompress, fileInfo)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	c.files[path] = cached
+	return os.Open(cached.path)
+}
+
+//Opens the log file like open once its decompressed copy is complete, the copies of other files aren't waited for
+func (c *decompressionCache) openComplete(path string) (*os.File, error) {
+	file, err := c.open(path)
+	if err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	cached, found := c.files[path]
+	c.mutex.Unlock()
+	if found {
+		<-cached.done
+	}
+	return file, nil
+}
+
+//Returns the file info of the decompressed copy of the file, which grows while it is decompressed
+//Returns false if the file has no copy
+func (c *decompressionCache) stat(path string) (os.FileInfo, bool) {
+	c.mutex.Lock()
+	cached, found := c.files[path]
+	c.mutex.Unlock()
+	if !found {
+		return nil, false
+	}
+	fileInfo, err := os.Stat(cached.path)
+	return fileInfo, err == nil
+}
+
+//Creates the copy of the compressed file and starts decompressing into it in the background, the compressed file is closed once done
+//Returns an error if the format header cannot be read or the copy cannot be created
+func (c *decompressionCache) startCopy(file *os.File, decompress decompressor, fileInfo os.FileInfo) (decompressedFile, error) {
+	reader, err := decompress(file)
+	if err != nil {
+		return decompressedFile{}, err
+	}
+	output, err := ioutil.TempFile("", "golog-*.log")
+	if err != nil {
+		reader.Close()
+		return decompressedFile{}, err
+	}
+
+	cached := decompressedFile{output.Name(), fileInfo.Size(), fileInfo.ModTime(), make(chan struct{}), make(chan struct{})}
+	go func() {
+		defer close(cached.done)
+		defer file.Close()
+		defer reader.Close()
+		defer output.Close()
+
+		//A corrupt archive keeps the part decompressed before the error
+		buffer := make([]byte, 256*1024)
+		progressed := time.Now()
+		for {
+			select {
+			case <-cached.stop:
+				return
+			default:
+			}
+			read, err := reader.Read(buffer)
+			if read > 0 {
+				if _, err := output.Write(buffer[:read]); err != nil {
+					break
+				}
+			}
+			if err != nil {
+				break
+			}
+			if c.grown != nil && time.Since(progressed) >= updateInterval {
+				c.grown()
+				progressed = time.Now()
+			}
+		}
+		if c.grown != nil {
+			c.grown()
+		}
+	}()
+	return cached, nil
+}
+
+//Stops decompressing the copy and removes it
+func (f decompressedFile) remove() {
+	close(f.stop)
+	<-f.done
+	os.Remove(f.path)
+}
+
+//Waits for the copies being decompressed to be complete
+func (c *decompressionCache) wait() {
+	c.mutex.Lock()
+	files := make([]decompressedFile, 0, len(c.files))
+	for _, cached := range c.files {
+		files = append(files, cached)
+	}
+	c.mutex.Unlock()
+
+	for _, cached := range files {
+		<-cached.done
+	}
+}
+
+//Stops decompressing and removes all decompressed copies from the temp directory
+func (c *decompressionCache) clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for path, cached := range c.files {
+		cached.remove()
+		delete(c.files, path)
+	}
+}
+
+//Reads the magic number at the start of the file
+//Returns the decompressor for the file's format or nil if the file isn't compressed
+func detectCompression(file io.Reader) decompressor {
+	header := make([]byte, 4)
+	n, _ := io.ReadFull(file, header)
+	for _, format := range compressions {
+		if bytes.HasPrefix(header[:n], format.magic) {
+			return format.decompress
+		}
+	}
+
+	return nil
+}
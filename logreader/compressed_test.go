@@ -0,0 +1,146 @@
+package logreader
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogReader_compressedFiles(t *testing.T) {
+	for _, input := range []string{
+		"../test_logs/TestLogReader_Compressed.log.gz",
+		"../test_logs/TestLogReader_Compressed.log.bz2",
+		"../test_logs/TestLogReader_Compressed.log.zst",
+	} {
+		logReader := NewLogReader(logreaderConfig(input, []int{10, 10, 10}))
+		logReader.SetCapacity(3)
+		logReader.Tail()
+		logReader.decompressed.wait()
+
+		expectedTail := [][]string{
+			{"16/11/2010", "Thread-6", "com.test"},
+			{"17/11/2010", "Thread-7", "com.test"},
+			{"18/11/2010", "Thread-8", "com.test"},
+		}
+		if result := *logReader.Tail(); !reflect.DeepEqual(result, expectedTail) {
+			t.Errorf(`%s tail: Expected %s got %s`, input, expectedTail, result)
+		}
+		if progress := logReader.Progress(); progress != 100 {
+			t.Errorf(`%s: Expected progress 100 after tail, got %d`, input, progress)
+		}
+
+		expectedPageUp := [][]string{
+			{"13/11/2010", "Thread-3", "com.test"},
+			{"14/11/2010", "Thread-4", "com.test"},
+			{"15/11/2010", "Thread-5", "com.test"},
+		}
+		if result := *logReader.PageUp(); !reflect.DeepEqual(result, expectedPageUp) {
+			t.Errorf(`%s page up: Expected %s got %s`, input, expectedPageUp, result)
+		}
+
+		expectedHead := [][]string{
+			{"11/11/2010", "Thread-1", "com.test"},
+			{"12/11/2010", "Thread-2", "com.test"},
+			{"13/11/2010", "Thread-3", "com.test"},
+		}
+		if result := *logReader.Head(); !reflect.DeepEqual(result, expectedHead) {
+			t.Errorf(`%s head: Expected %s got %s`, input, expectedHead, result)
+		}
+		logReader.Close()
+	}
+}
+
+func TestDecompressionCache_reusesCopyAndClears(t *testing.T) {
+	cache := newDecompressionCache(nil)
+	first, err := cache.open("../test_logs/TestLogReader_Compressed.log.gz")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	first.Close()
+	second, _ := cache.open("../test_logs/TestLogReader_Compressed.log.gz")
+	second.Close()
+
+	if first.Name() != second.Name() {
+		t.Errorf("Expected the decompressed copy %s to be reused, got %s", first.Name(), second.Name())
+	}
+
+	cache.clear()
+	if _, err := os.Stat(first.Name()); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be removed", first.Name())
+	}
+}
+
+func TestDecompressionCache_plainFileIsOpenedDirectly(t *testing.T) {
+	input := "../test_logs/TestLogReader_Tail_input.log"
+	file, err := newDecompressionCache(nil).open(input)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	defer file.Close()
+
+	if file.Name() != input {
+		t.Errorf("Expected %s to be opened directly, got %s", input, file.Name())
+	}
+	if position, _ := file.Seek(0, 1); position != 0 {
+		t.Errorf("Expected the file to be rewound after detecting the format, got position %d", position)
+	}
+}
+
+func TestDecompressionCache_reportsTheCopyGrowing(t *testing.T) {
+	grown := make(chan struct{}, 1)
+	cache := newDecompressionCache(func() {
+		select {
+		case grown <- struct{}{}:
+		default:
+		}
+	})
+	defer cache.clear()
+	file, err := cache.open("../test_logs/TestLogReader_Compressed.log.gz")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	defer file.Close()
+
+	select {
+	case <-grown:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected the copy to be reported once decompressed")
+	}
+	cache.wait()
+	content, _ := ioutil.ReadAll(file)
+	if lines := strings.Count(string(content), "\n"); lines != 8 {
+		t.Errorf("Expected the 8 lines of the log once decompressed, got %d", lines)
+	}
+}
+
+func TestDecompressionCache_openCompleteWaitsForItsOwnCopy(t *testing.T) {
+	cache := newDecompressionCache(nil)
+	defer cache.clear()
+	//A copy of another file that is never complete
+	pending, _ := ioutil.TempFile("", "golog-*.log")
+	pending.Close()
+	cache.files["other.log.gz"] = decompressedFile{path: pending.Name(), stop: make(chan struct{}), done: make(chan struct{})}
+	defer func() {
+		close(cache.files["other.log.gz"].done)
+	}()
+
+	opened := make(chan error, 1)
+	go func() {
+		file, err := cache.openComplete("../test_logs/TestLogReader_Compressed.log.gz")
+		if err == nil {
+			file.Close()
+		}
+		opened <- err
+	}()
+	select {
+	case err := <-opened:
+		if err != nil {
+			t.Errorf("Unexpected error %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("Expected the copy of another file not to be waited for")
+	}
+}
@@ -0,0 +1,235 @@
+package logreader
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+//An exception read from the stack trace of a log entry, Java exceptions with their Caused by chain, Go panics and goroutine dumps, and Python tracebacks
+//The frames start where the exception was thrown, Python frames are reversed to match
+type Exception struct {
+	Type    string
+	Message string
+	Frames  []StackFrame
+	//The exception that caused this one, the exception being handled when a Python exception or a Go panic was raised
+	Cause *Exception
+	//The lines of the entry the stack trace spans, counting from 0, EndLine is the line after the trace
+	StartLine int
+	EndLine   int
+	//The line of the entry the type and message were read from and the line of each frame, the first line of frames spanning two
+	HeaderLine int
+	FrameLines []int
+}
+
+//A call in a stack trace, File and Line are empty if the trace doesn't have them
+type StackFrame struct {
+	Function string
+	File     string
+	Line     int
+}
+
+var (
+	javaException  = regexp.MustCompile(`^\s*(?:Exception in thread "[^"]*" )?((?:[a-zA-Z_$][\w$]*\.)+[a-zA-Z_$][\w$]*)(?::\s?(.*))?$`)
+	javaFrame      = regexp.MustCompile(`^\s+at\s+(\S+?)\((.*)\)\s*$`)
+	javaOmitted    = regexp.MustCompile(`^\s+\.\.\. \d+ (?:more|common frames omitted)\s*$`)
+	javaCause      = regexp.MustCompile(`^\s*Caused by: (.*)$`)
+	javaSuppressed = regexp.MustCompile(`^(\s+)Suppressed: `)
+
+	goPanic     = regexp.MustCompile(`^\s*(panic|fatal error): (.*?)(?: \[recovered\])?$`)
+	goGoroutine = regexp.MustCompile(`^goroutine (\d+ \[.*\]):$`)
+	goFile      = regexp.MustCompile(`^\t(.+?):(\d+)(?: \+0x[0-9a-f]+)?$`)
+
+	pythonTraceback = regexp.MustCompile(`^\s*Traceback \(most recent call last\):$`)
+	pythonFrame     = regexp.MustCompile(`^\s+File "(.*)", line (\d+)(?:, in (.*))?$`)
+	pythonException = regexp.MustCompile(`^([a-zA-Z_][\w.]*)(?:: (.*))?$`)
+	pythonChain     = regexp.MustCompile(`^(?:During handling of the above exception, another exception occurred|The above exception was the direct cause of the following exception):$`)
+)
+
+//Finds the first stack trace in the lines of the log entry
+//Returns nil if the entry has no Java, Go or Python stack trace
+func ParseException(entry string) *Exception {
+	lines := strings.Split(strings.Replace(entry, "\r\n", "\n", -1), "\n")
+	for index, line := range lines {
+		var exception *Exception
+		switch {
+		case pythonTraceback.MatchString(line):
+			exception = parsePythonTraceback(lines, index)
+		case goPanic.MatchString(line) || goGoroutine.MatchString(line):
+			exception = parseGoPanic(lines, index)
+		case javaException.MatchString(line) && index+1 < len(lines) && javaFrame.MatchString(lines[index+1]):
+			exception = parseJavaException(lines, index)
+		}
+		if exception != nil {
+			return exception
+		}
+	}
+	return nil
+}
+
+//Returns the function followed by its file and line, such as com.test.Service.run(Service.java:12)
+func (f StackFrame) String() string {
+	switch {
+	case f.File == "":
+		return f.Function
+	case f.Line == 0:
+		return fmt.Sprintf("%s(%s)", f.Function, f.File)
+	}
+	return fmt.Sprintf("%s(%s:%d)", f.Function, f.File, f.Line)
+}
+
+//Returns true if the function or the file of the frame starts with one of the prefixes, such as the packages of the application
+func (f StackFrame) InPackages(prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if prefix != "" && (strings.HasPrefix(f.Function, prefix) || strings.HasPrefix(f.File, prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+//Reads a Java exception starting at the line, its frames and the exceptions in its Caused by chain
+func parseJavaException(lines []string, start int) *Exception {
+	match := javaException.FindStringSubmatch(lines[start])
+	top := &Exception{Type: match[1], Message: match[2], StartLine: start, HeaderLine: start}
+	exception := top
+	//The indentation of the Suppressed: block being skipped, -1 outside of one
+	suppressed := -1
+	index := start + 1
+	for ; index < len(lines); index++ {
+		line := lines[index]
+		indentation := len(line) - len(strings.TrimLeft(line, " \t"))
+		if suppressed >= 0 && indentation > suppressed {
+			continue
+		}
+		suppressed = -1
+
+		if frame := javaFrame.FindStringSubmatch(line); frame != nil {
+			exception.Frames = append(exception.Frames, javaStackFrame(frame[1], frame[2]))
+			exception.FrameLines = append(exception.FrameLines, index)
+		} else if cause := javaCause.FindStringSubmatch(line); cause != nil {
+			header := javaException.FindStringSubmatch(cause[1])
+			if header == nil {
+				break
+			}
+			exception.Cause = &Exception{Type: header[1], Message: header[2], HeaderLine: index}
+			exception = exception.Cause
+		} else if match := javaSuppressed.FindStringSubmatch(line); match != nil {
+			suppressed = len(match[1])
+		} else if !javaOmitted.MatchString(line) {
+			break
+		}
+	}
+	top.EndLine = index
+	return top
+}
+
+//Splits the location of a Java frame, such as Service.java:12, Native Method or Unknown Source
+func javaStackFrame(function string, location string) StackFrame {
+	frame := StackFrame{Function: function, File: location}
+	if separator := strings.LastIndex(location, ":"); separator != -1 {
+		if line, err := strconv.Atoi(location[separator+1:]); err == nil {
+			frame.File, frame.Line = location[:separator], line
+		}
+	}
+	return frame
+}
+
+//Reads the Go panics starting at the line and the frames of the first goroutine
+//A dump without a panic is read as an exception of type goroutine, a panic raised while another one was recovering has it as its cause
+func parseGoPanic(lines []string, start int) *Exception {
+	var exception *Exception
+	index := start
+	for ; index < len(lines); index++ {
+		if match := goPanic.FindStringSubmatch(lines[index]); match != nil {
+			exception = &Exception{Type: match[1], Message: match[2], Cause: exception, HeaderLine: index}
+		} else if strings.TrimSpace(lines[index]) != "" && !strings.HasPrefix(lines[index], "[signal ") {
+			break
+		}
+	}
+
+	if index == len(lines) || !goGoroutine.MatchString(lines[index]) {
+		if exception == nil {
+			return nil
+		}
+		exception.StartLine, exception.EndLine = start, index
+		return exception
+	}
+	if exception == nil {
+		exception = &Exception{Type: "goroutine", Message: goGoroutine.FindStringSubmatch(lines[index])[1], HeaderLine: index}
+	}
+
+	for index++; index+1 < len(lines); index += 2 {
+		file := goFile.FindStringSubmatch(lines[index+1])
+		if file == nil || strings.TrimSpace(lines[index]) == "" {
+			break
+		}
+		line, _ := strconv.Atoi(file[2])
+		exception.Frames = append(exception.Frames, StackFrame{goFunction(lines[index]), file[1], line})
+		exception.FrameLines = append(exception.FrameLines, index)
+	}
+	exception.StartLine, exception.EndLine = start, index
+	return exception
+}
+
+//Returns the function called in the line of a goroutine, without its arguments
+func goFunction(line string) string {
+	function := strings.TrimPrefix(line, "created by ")
+	if goroutine := strings.Index(function, " in goroutine "); goroutine != -1 {
+		function = function[:goroutine]
+	}
+	if strings.HasSuffix(function, ")") {
+		if arguments := strings.LastIndex(function, "("); arguments > 0 {
+			function = function[:arguments]
+		}
+	}
+	return function
+}
+
+//Reads the Python traceback starting at the line and the tracebacks chained after it
+//The last traceback is the exception raised, each traceback before it is the cause of the one after it
+func parsePythonTraceback(lines []string, start int) *Exception {
+	var exception *Exception
+	index := start
+	for index < len(lines) && pythonTraceback.MatchString(lines[index]) {
+		var frames []StackFrame
+		var frameLines []int
+		for index++; index < len(lines); index++ {
+			if frame := pythonFrame.FindStringSubmatch(lines[index]); frame != nil {
+				line, _ := strconv.Atoi(frame[2])
+				frames = append([]StackFrame{{frame[3], frame[1], line}}, frames...)
+				frameLines = append([]int{index}, frameLines...)
+			} else if !strings.HasPrefix(lines[index], " ") {
+				break
+			}
+		}
+		if index == len(lines) {
+			break
+		}
+		match := pythonException.FindStringSubmatch(lines[index])
+		if match == nil {
+			break
+		}
+		exception = &Exception{Type: match[1], Message: match[2], Frames: frames, Cause: exception, HeaderLine: index, FrameLines: frameLines}
+		index++
+
+		//A chained traceback follows after a blank line, the message and another blank line
+		next := index
+		for next < len(lines) && strings.TrimSpace(lines[next]) == "" {
+			next++
+		}
+		if next == len(lines) || !pythonChain.MatchString(lines[next]) {
+			break
+		}
+		for next++; next < len(lines) && strings.TrimSpace(lines[next]) == ""; next++ {
+		}
+		index = next
+	}
+
+	if exception == nil {
+		return nil
+	}
+	exception.StartLine, exception.EndLine = start, index
+	return exception
+}
@@ -0,0 +1,139 @@
+package logreader
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestException_java(t *testing.T) {
+	content, _ := ioutil.ReadFile("../test_logs/TestLogReader_Navigate_withstacktrace.log")
+	exception := ParseException(string(content))
+	if exception == nil {
+		t.Fatalf("Expected the Java stack trace to be found")
+	}
+
+	if exception.Type != "java.lang.IllegalStateException" || exception.Message != "Exception thrown on Scheduler.Worker thread. Add `onError` handling." {
+		t.Errorf("Unexpected exception %q: %q", exception.Type, exception.Message)
+	}
+	if expected := (StackFrame{"rx.internal.schedulers.ScheduledAction.run", "ScheduledAction.java", 50}); exception.Frames[0] != expected {
+		t.Errorf("Expected the top frame %v, got %v", expected, exception.Frames[0])
+	}
+	if expected := (StackFrame{"java.lang.reflect.Method.invokeNative", "Method.java", 0}); exception.Frames[5] != expected {
+		t.Errorf("Expected a frame without a line %v, got %v", expected, exception.Frames[5])
+	}
+
+	var causes []string
+	for cause := exception.Cause; cause != nil; cause = cause.Cause {
+		causes = append(causes, cause.Type)
+	}
+	if expected := []string{"rx.exceptions.OnErrorNotImplementedException", "rx.exceptions.MissingBackpressureException"}; !reflect.DeepEqual(causes, expected) {
+		t.Errorf("Expected the causes %v, got %v", expected, causes)
+	}
+	if exception.StartLine != 12 || exception.EndLine != 55 {
+		t.Errorf("Expected the trace on lines 12 to 55, got %d to %d", exception.StartLine, exception.EndLine)
+	}
+}
+
+func TestException_javaSuppressedAndOmitted(t *testing.T) {
+	exception := ParseException("Exception in thread \"main\" java.io.IOException: closed\n" +
+		"\tat com.test.Store.close(Store.java:12)\n" +
+		"\tSuppressed: java.lang.IllegalStateException\n" +
+		"\t\tat com.test.Store.flush(Store.java:40)\n" +
+		"\t\t... 1 more\n" +
+		"Caused by: java.net.SocketException: reset\n" +
+		"\tat java.net.Socket.read(Native Method)\n" +
+		"\t... 3 more\n" +
+		"done")
+
+	if len(exception.Frames) != 1 || exception.Cause == nil || exception.Cause.Message != "reset" {
+		t.Fatalf("Expected the suppressed exception to be skipped, got %+v", exception)
+	}
+	if expected := (StackFrame{"java.net.Socket.read", "Native Method", 0}); exception.Cause.Frames[0] != expected {
+		t.Errorf("Expected %v, got %v", expected, exception.Cause.Frames[0])
+	}
+	if exception.EndLine != 8 {
+		t.Errorf("Expected the trace to end before the last line, got %d", exception.EndLine)
+	}
+	if exception.HeaderLine != 0 || exception.Cause.HeaderLine != 5 || !reflect.DeepEqual(exception.FrameLines, []int{1}) || !reflect.DeepEqual(exception.Cause.FrameLines, []int{6}) {
+		t.Errorf("Expected the lines of the headers and frames, got %d %v and %d %v", exception.HeaderLine, exception.FrameLines, exception.Cause.HeaderLine, exception.Cause.FrameLines)
+	}
+}
+
+func TestException_goPanic(t *testing.T) {
+	exception := ParseException("2024/01/02 10:00:00 handling request\n" +
+		"panic: runtime error: invalid memory address or nil pointer dereference [recovered]\n" +
+		"\tpanic: closed\n" +
+		"[signal SIGSEGV: segmentation violation code=0x1 addr=0x0 pc=0x4a1f2e]\n" +
+		"\n" +
+		"goroutine 7 [running]:\n" +
+		"github.com/acme/app/server.(*Server).handle(0xc000010000, {0x0, 0x0})\n" +
+		"\t/src/app/server/server.go:42 +0x1e\n" +
+		"created by github.com/acme/app/server.Start in goroutine 1\n" +
+		"\t/src/app/server/server.go:20 +0x85\n" +
+		"\n" +
+		"goroutine 1 [chan receive]:\n" +
+		"main.main()\n" +
+		"\t/src/app/main.go:9 +0x30\n")
+
+	if exception.Type != "panic" || exception.Message != "closed" || exception.Cause == nil || exception.Cause.Message != "runtime error: invalid memory address or nil pointer dereference" {
+		t.Fatalf("Unexpected panics %+v", exception)
+	}
+	expected := []StackFrame{
+		{"github.com/acme/app/server.(*Server).handle", "/src/app/server/server.go", 42},
+		{"github.com/acme/app/server.Start", "/src/app/server/server.go", 20},
+	}
+	if !reflect.DeepEqual(exception.Frames, expected) {
+		t.Errorf("Expected the frames of the panicking goroutine %v, got %v", expected, exception.Frames)
+	}
+	if exception.StartLine != 1 || exception.EndLine != 10 {
+		t.Errorf("Expected the trace on lines 1 to 10, got %d to %d", exception.StartLine, exception.EndLine)
+	}
+
+	dump := ParseException("goroutine 1 [running]:\nmain.main()\n\t/src/app/main.go:9 +0x30")
+	if dump.Type != "goroutine" || dump.Message != "1 [running]" || len(dump.Frames) != 1 {
+		t.Errorf("Unexpected goroutine dump %+v", dump)
+	}
+}
+
+func TestException_pythonChain(t *testing.T) {
+	exception := ParseException("ERROR request failed\n" +
+		"Traceback (most recent call last):\n" +
+		"  File \"/app/store.py\", line 10, in load\n" +
+		"    return cache[key]\n" +
+		"KeyError: 'user'\n" +
+		"\n" +
+		"During handling of the above exception, another exception occurred:\n" +
+		"\n" +
+		"Traceback (most recent call last):\n" +
+		"  File \"/app/main.py\", line 4, in <module>\n" +
+		"    main()\n" +
+		"  File \"/app/main.py\", line 2, in main\n" +
+		"    load('user')\n" +
+		"ValueError: missing user\n" +
+		"INFO next request")
+
+	if exception.Type != "ValueError" || exception.Message != "missing user" || exception.Cause == nil || exception.Cause.Type != "KeyError" {
+		t.Fatalf("Unexpected exceptions %+v", exception)
+	}
+	expected := []StackFrame{{"main", "/app/main.py", 2}, {"<module>", "/app/main.py", 4}}
+	if !reflect.DeepEqual(exception.Frames, expected) {
+		t.Errorf("Expected the frames where it was raised first %v, got %v", expected, exception.Frames)
+	}
+	if exception.StartLine != 1 || exception.EndLine != 14 {
+		t.Errorf("Expected the trace on lines 1 to 14, got %d to %d", exception.StartLine, exception.EndLine)
+	}
+	if ParseException("12/11/2010~Thread-2~com.test\nno trace here") != nil {
+		t.Errorf("Expected no exception")
+	}
+}
+
+func TestStackFrame_InPackages(t *testing.T) {
+	frame := StackFrame{"com.test.Service.run", "Service.java", 12}
+	if !frame.InPackages([]string{"org.other", "com.test"}) || frame.InPackages([]string{"com.testing"}) || frame.InPackages(nil) {
+		t.Errorf("Unexpected package matches for %v", frame)
+	}
+	if frame.String() != "com.test.Service.run(Service.java:12)" {
+		t.Errorf("Unexpected frame text %q", frame.String())
+	}
+}
@@ -0,0 +1,120 @@
+package logreader
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+//The stack traces of a log with the same fingerprint, the same exception type thrown from the same frames, see LogReader.ExceptionGroups
+type ExceptionGroup struct {
+	Fingerprint string
+	//The exception of the first entry in the group
+	Exception *Exception
+	Count     int
+	//The lines of the first and last entries in the view, counting from 1
+	FirstLine int
+	LastLine  int
+	//The times of the first and last entries, zero if the log has no timestamp column
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+//Returns the type of the exception followed by the functions of its top frames, the line numbers are left out so the fingerprint survives small changes to the code
+func exceptionFingerprint(exception *Exception, frames int) string {
+	fingerprint := []string{exception.Type}
+	for index, frame := range exception.Frames {
+		if index == frames {
+			break
+		}
+		fingerprint = append(fingerprint, frame.Function)
+	}
+	return strings.Join(fingerprint, "|")
+}
+
+//Reads the entries of the active file as it is shown in the background and groups their stack traces by the exception type and the top frames
+//done is called from the background with the groups, the most entries first and groups of the same size in the order they first appear
+func (l *LogReader) ExceptionGroups(frames int, done func(groups []ExceptionGroup)) {
+	file, err := l.openLogFile()
+	parser := l.parser()
+	timestamps := l.timestampsOf(l.FileIndex)
+	go func() {
+		if err != nil {
+			done(nil)
+			return
+		}
+		defer file.Close()
+		done(groupExceptions(file, parser, timestamps, frames))
+	}()
+}
+
+//Reads the entries of the log from its start and groups their stack traces, see ExceptionGroups
+func groupExceptions(file logSource, parser lineParser, timestamps *timestampParser, frames int) []ExceptionGroup {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil
+	}
+
+	var groups []ExceptionGroup
+	indexes := make(map[string]int)
+	//Adds the entry read from its first line in the log and the text of its lines
+	add := func(first string, lines []string, line int) {
+		//A stack trace spans more than one line
+		entry := strings.Join(lines, "\n")
+		if !strings.Contains(entry, "\n") {
+			return
+		}
+		exception := ParseException(entry)
+		if exception == nil {
+			return
+		}
+
+		var seen time.Time
+		if timestamps != nil && parser.isEntry(first) {
+			seen, _ = timestamps.parse(parser.parse(first))
+		}
+		fingerprint := exceptionFingerprint(exception, frames)
+		index, ok := indexes[fingerprint]
+		if !ok {
+			index = len(groups)
+			indexes[fingerprint] = index
+			groups = append(groups, ExceptionGroup{Fingerprint: fingerprint, Exception: exception, FirstLine: line, FirstSeen: seen})
+		}
+		groups[index].Count++
+		groups[index].LastLine = line
+		if !seen.IsZero() {
+			groups[index].LastSeen = seen
+		}
+	}
+
+	reader := bufio.NewReader(file)
+	var entry []string
+	first := ""
+	entryLine := 1
+	for line := 1; ; line++ {
+		data, err := reader.ReadString('\n')
+		if len(data) > 0 {
+			text := strings.TrimRight(data, "\r\n")
+			if parser.isEntry(text) && len(entry) > 0 {
+				add(first, entry, entryLine)
+				entry = nil
+			}
+			if len(entry) == 0 {
+				first, entryLine = text, line
+			}
+			entry = append(entry, logText(parser, text))
+		}
+		if err != nil {
+			break
+		}
+	}
+	if len(entry) > 0 {
+		add(first, entry, entryLine)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Count > groups[j].Count
+	})
+	return groups
+}
@@ -0,0 +1,69 @@
+package logreader
+
+import (
+	"testing"
+	"time"
+)
+
+const groupedTraces = "11/11/2010~Thread-1~com.test\n" +
+	"java.lang.IllegalStateException: closed\n" +
+	"\tat com.test.Store.read(Store.java:10)\n" +
+	"\tat com.test.Service.run(Service.java:20)\n" +
+	"12/11/2010~Thread-2~com.test\n" +
+	"java.lang.NullPointerException\n" +
+	"\tat com.test.Service.run(Service.java:22)\n" +
+	"13/11/2010~Thread-3~com.test\n" +
+	"java.lang.IllegalStateException: closed again\n" +
+	"\tat com.test.Store.read(Store.java:12)\n" +
+	"\tat com.test.Service.run(Service.java:20)\n" +
+	"14/11/2010~Thread-4~com.test\n" +
+	"java.lang.IllegalStateException: closed\n" +
+	"\tat com.test.Store.write(Store.java:30)\n"
+
+//Groups the stack traces of the active file and waits for the groups
+func waitForExceptionGroups(logReader *LogReader, frames int) []ExceptionGroup {
+	groups := make(chan []ExceptionGroup, 1)
+	logReader.ExceptionGroups(frames, func(found []ExceptionGroup) {
+		groups <- found
+	})
+	return <-groups
+}
+
+func TestLogReader_ExceptionGroups(t *testing.T) {
+	path, cleanup := tempLog(t, groupedTraces)
+	defer cleanup()
+
+	config := logreaderConfig(path, []int{10, 10, 10})
+	config.TimestampColumn = "Date"
+	config.TimestampLayout = "02/01/2006"
+	logReader := NewLogReader(config)
+	defer logReader.Close()
+
+	groups := waitForExceptionGroups(&logReader, 2)
+	if len(groups) != 3 {
+		t.Fatalf("Expected 3 groups, got %+v", groups)
+	}
+	first := groups[0]
+	if first.Fingerprint != "java.lang.IllegalStateException|com.test.Store.read|com.test.Service.run" || first.Count != 2 {
+		t.Errorf("Expected the traces differing only in their message and lines grouped, got %+v", first)
+	}
+	if first.FirstLine != 1 || first.LastLine != 8 || first.Exception.Message != "closed" {
+		t.Errorf("Expected the group from line 1 to 8, got %+v", first)
+	}
+	if !first.FirstSeen.Equal(time.Date(2010, 11, 11, 0, 0, 0, 0, time.UTC)) || !first.LastSeen.Equal(time.Date(2010, 11, 13, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected first and last seen %v %v", first.FirstSeen, first.LastSeen)
+	}
+	if groups[1].Exception.Type != "java.lang.NullPointerException" || groups[2].FirstLine != 12 {
+		t.Errorf("Expected the single traces in the order they appear, got %+v", groups[1:])
+	}
+
+	if groups := waitForExceptionGroups(&logReader, 0); len(groups) != 2 || groups[0].Count != 3 {
+		t.Errorf("Expected the traces grouped by type without frames, got %+v", groups)
+	}
+
+	//Collapsing only changes the rows, the groups keep the lines of the log
+	logReader.SetCollapsed(true)
+	if groups := waitForExceptionGroups(&logReader, 2); len(groups) != 3 || groups[0].LastLine != 8 || groups[2].FirstLine != 12 {
+		t.Errorf("Expected the lines of the log, got %+v", groups)
+	}
+}
@@ -0,0 +1,208 @@
+package logreader
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+//Size of the buffer the filtered copy is written with, the part written is readable each time it's flushed
+const filteredBufferSize = 64 * 1024
+
+//Shows only the log entries matching the matcher, or with Exclude only the entries not matching it
+type Filter struct {
+	Matcher Matcher
+	Exclude bool
+}
+
+//Returns true if the line read by the parser passes all filters, filters stack so every include filter must match and no exclude filter may match
+func passesFilters(filters []Filter, parser lineParser, line string) bool {
+	for _, filter := range filters {
+		if matchLine(filter.Matcher, parser, line) == filter.Exclude {
+			return false
+		}
+	}
+	return true
+}
+
+//The entries of a log passing the filters, copied to a temp file so the filtered view can be navigated like a log file
+//The copy is written in the background and can be read while it's written, it's appended to as the log grows
+//Each entry is matched together with its continuation lines, the last entry is matched again as continuation lines are added to it
+type filteredView struct {
+	mutex    sync.Mutex
+	path     string
+	updating bool
+	running  sync.WaitGroup
+	stopped  int32
+	//The state of the copy, only changed by the update running in the background
+	fileInfo os.FileInfo
+	offset   int64
+	//Where the last record read starts in the log and the size of the copy before it
+	lastRecord int64
+	lastCopied int64
+	index      *lineIndex
+}
+
+func newFilteredView() *filteredView {
+	return &filteredView{index: newLineIndex("", "")}
+}
+
+//Starts copying the entries added to the log since the last update that pass the filters in the background, creating the copy on the first update
+//The source is closed once it's copied, progress is called every time a part of the copy can be read
+//Does nothing if an update is already running or the view was removed
+//Returns an error if the copy cannot be created
+func (v *filteredView) refresh(source logSource, fileInfo os.FileInfo, parser lineParser, filters []Filter, progress func()) error {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if v.updating || atomic.LoadInt32(&v.stopped) == 1 {
+		source.Close()
+		return nil
+	}
+	if v.path == "" {
+		filtered, err := ioutil.TempFile("", "golog-filtered-*.log")
+		if err != nil {
+			source.Close()
+			return err
+		}
+		v.path = filtered.Name()
+		filtered.Close()
+	}
+	v.updating = true
+	v.running.Add(1)
+	go func() {
+		defer v.running.Done()
+		defer source.Close()
+		v.update(source, fileInfo, parser, filters, progress)
+		progress()
+
+		v.mutex.Lock()
+		v.updating = false
+		v.mutex.Unlock()
+	}()
+	return nil
+}
+
+//Waits for the update running in the background to finish
+func (v *filteredView) wait() {
+	v.running.Wait()
+}
+
+//Copies the entries added to the log since the last update that pass the filters
+//The copy is started over if the log was truncated or replaced, the update stops early once the view is removed
+//Returns an error if the copy cannot be written
+func (v *filteredView) update(source logSource, fileInfo os.FileInfo, parser lineParser, filters []Filter, progress func()) error {
+	sourceInfo, err := source.Stat()
+	if err != nil {
+		return err
+	}
+	if v.fileInfo != nil && (!os.SameFile(v.fileInfo, fileInfo) || sourceInfo.Size() < v.offset) {
+		if err := os.Truncate(v.path, 0); err != nil {
+			return err
+		}
+		v.index.truncate(0)
+		v.offset = 0
+		v.lastRecord = 0
+		v.lastCopied = 0
+	}
+	v.fileInfo = fileInfo
+	if sourceInfo.Size() == v.offset {
+		return nil
+	}
+	//The last record is read again, continuation lines may have been added to it
+	if v.offset > v.lastRecord {
+		if err := os.Truncate(v.path, v.lastCopied); err != nil {
+			return err
+		}
+		v.index.truncate(v.lastCopied)
+		v.offset = v.lastRecord
+	}
+
+	if _, err := source.Seek(v.offset, io.SeekStart); err != nil {
+		return err
+	}
+	filtered, err := os.OpenFile(v.path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer filtered.Close()
+
+	copied, err := filtered.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(io.LimitReader(source, sourceInfo.Size()-v.offset))
+	writer := bufio.NewWriterSize(filtered, filteredBufferSize)
+	progressed := time.Now()
+	//The lines of the record read last as they are in the log
+	var record []string
+	//Copies the record if it passes the filters, the copy is written in whole records so the part written can be read
+	copyRecord := func() error {
+		texts := make([]string, len(record))
+		size := 0
+		for index, line := range record {
+			texts[index] = strings.TrimRight(line, "\r\n")
+			size += len(line)
+		}
+		if !passesFilters(filters, recordParser{parser}, strings.Join(texts, recordSeparator)) {
+			return nil
+		}
+		if writer.Available() < size {
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+			if time.Since(progressed) >= updateInterval {
+				progress()
+				progressed = time.Now()
+			}
+		}
+		for _, line := range record {
+			writer.WriteString(line)
+		}
+		copied += int64(size)
+		return nil
+	}
+	for atomic.LoadInt32(&v.stopped) == 0 {
+		line, err := reader.ReadString('\n')
+		//A line still being written is left for the next update
+		if err != nil || !strings.HasSuffix(line, "\n") {
+			break
+		}
+		if parser.isEntry(strings.TrimRight(line, "\r\n")) && len(record) > 0 {
+			if err := copyRecord(); err != nil {
+				return err
+			}
+			record = nil
+		}
+		if len(record) == 0 {
+			v.lastRecord, v.lastCopied = v.offset, copied
+		}
+		record = append(record, line)
+		v.offset += int64(len(line))
+	}
+	if len(record) > 0 {
+		if err := copyRecord(); err != nil {
+			return err
+		}
+	}
+
+	return writer.Flush()
+}
+
+//Stops the update running in the background and removes the copy
+func (v *filteredView) remove() {
+	atomic.StoreInt32(&v.stopped, 1)
+	v.wait()
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if v.path != "" {
+		os.Remove(v.path)
+	}
+}
@@ -0,0 +1,177 @@
+package logreader
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func filter(term string, exclude bool) Filter {
+	matcher, _ := NewMatcher(term, SearchOptions{Regex: true})
+	return Filter{matcher, exclude}
+}
+
+//Copies the entries of the active file added since the copy running, if any, and waits until they are copied
+func waitForFilters(logReader *LogReader) {
+	logReader.filtered[logReader.FileIndex].wait()
+	if file, err := logReader.openLogFile(); err == nil {
+		file.Close()
+	}
+	logReader.filtered[logReader.FileIndex].wait()
+}
+
+func TestLogReader_SetFilters_stackIncludeAndExclude(t *testing.T) {
+	input := "../test_logs/TestLogReader_Tail_input.log"
+	logReader := NewLogReader(logreaderConfig(input, []int{10, 10, 10}))
+	defer logReader.Close()
+	logReader.SetCapacity(3)
+	logReader.SetFilters([]Filter{filter(`Thread-[2-6]\b`, false), filter(`1[45]/11`, true)})
+	waitForFilters(&logReader)
+
+	expected := [][]string{{"12/11/2010", "Thread-2", "com.test"}, {"13/11/2010", "Thread-3", "com.test"}, {"16/11/2010", "Thread-6", "com.test"}}
+	if result := *logReader.Head(); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+	if progress := logReader.Progress(); progress != 100 {
+		t.Errorf("Expected the whole filtered view on the page, got %d%%", progress)
+	}
+
+	logReader.SetFilters(nil)
+	if result := *logReader.Head(); len(result) != 3 || result[0][1] != "Thread-1" {
+		t.Errorf("Expected every entry once the filters are cleared, got %s", result)
+	}
+}
+
+func TestLogReader_SetFilters_keepsContinuationLinesWithTheirEntry(t *testing.T) {
+	input := "../test_logs/TestLogReader_Navigate_withstacktrace.log"
+	logReader := NewLogReader(logreaderConfig(input, []int{10, 10, 10}))
+	defer logReader.Close()
+	logReader.SetCapacity(3)
+	logReader.SetFilters([]Filter{filter(`Thread-1[12]`, false)})
+	waitForFilters(&logReader)
+
+	//The stack trace of Thread-12 doesn't fit on the page of Thread-11, the next page starts with it
+	expected := [][]string{{"14/11/2010", "Thread-11", "com.test"}}
+	if result := *logReader.Head(); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+	expected = [][]string{
+		{"14/11/2010", "Thread-12", "com.test"},
+		{"java.lang.IllegalStateException: Exception thrown on Scheduler.Worker thread. Add `onError` handling."},
+		{"       at rx.internal.schedulers.ScheduledAction.run(ScheduledAction.java:50)"},
+	}
+	if result := *logReader.PageDown(); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+	//The stack trace of Thread-12 is in the view, the trailing entry isn't
+	if result := *logReader.Tail(); result[len(result)-1][0] == "18/11/2010" {
+		t.Errorf("Expected the entries not matching to be hidden, got %s", result)
+	}
+}
+
+func TestLogReader_SetFilters_tailsAppendedEntries(t *testing.T) {
+	path, cleanup := tempLog(t, "11/11/2010~Thread-1~com.test\n12/11/2010~Thread-2~com.test\n")
+	defer cleanup()
+
+	logReader := NewLogReader(logreaderConfig(path, []int{10, 10, 10}))
+	defer logReader.Close()
+	logReader.SetCapacity(2)
+	logReader.SetFilters([]Filter{filter(`Thread-1~`, false)})
+	logReader.Tail()
+
+	file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	file.WriteString("13/11/2010~Thread-3~com.test\n14/11/2010~Thread-1~com.test\n")
+	file.Close()
+	waitForFilters(&logReader)
+
+	expected := [][]string{{"11/11/2010", "Thread-1", "com.test"}, {"14/11/2010", "Thread-1", "com.test"}}
+	if result := *logReader.Tail(); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+	if notice := logReader.RotationNotice(); notice != "" {
+		t.Errorf("Expected no rotation notice, got %q", notice)
+	}
+}
+
+//Matches the lines with the thread in the column named Thread
+type threadMatcher string
+
+func (m threadMatcher) Match(line string) bool {
+	return false
+}
+
+func (m threadMatcher) FindAll(text string) [][]int {
+	return nil
+}
+
+func (m threadMatcher) MatchColumns(headers []string, line string, columns []string) bool {
+	for index, header := range headers {
+		if header == "Thread" && index < len(columns) {
+			return columns[index] == string(m)
+		}
+	}
+	return false
+}
+
+func TestLogReader_SetFilters_columnMatcher(t *testing.T) {
+	input := "../test_logs/TestLogReader_Tail_input.log"
+	logReader := NewLogReader(logreaderConfig(input, []int{10, 10, 10}))
+	defer logReader.Close()
+	logReader.SetCapacity(1)
+	logReader.SetFilters([]Filter{{Matcher: threadMatcher("Thread-4")}})
+	waitForFilters(&logReader)
+
+	expected := [][]string{{"14/11/2010", "Thread-4", "com.test"}}
+	if result := *logReader.Head(); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+}
+
+func TestLogReader_SetFilters_perFile(t *testing.T) {
+	input := "../test_logs/TestLogReader_Tail_input.log"
+	config := logreaderConfig(input, []int{10, 10, 10})
+	config.Files = append(config.Files, LogFile{LogFile: input, Name: "Other"})
+	logReader := NewLogReader(config)
+	defer logReader.Close()
+	logReader.SetCapacity(1)
+
+	logReader.FileIndex = 1
+	logReader.Head()
+	logReader.FileIndex = 0
+	logReader.SetFilters([]Filter{filter(`Thread-4`, false)})
+	waitForFilters(&logReader)
+	if result := *logReader.Head(); len(result) != 1 || result[0][1] != "Thread-4" {
+		t.Errorf("Expected the filtered entry, got %s", result)
+	}
+
+	logReader.FileIndex = 1
+	if filters := logReader.Filters(); len(filters) != 0 {
+		t.Errorf("Expected the other file to keep its own filters, got %v", filters)
+	}
+	if result := *logReader.Refresh(); len(result) != 1 || result[0][1] != "Thread-1" {
+		t.Errorf("Expected the other file to keep its position and entries, got %s", result)
+	}
+}
+
+func TestLogReader_SetFilters_copiesInTheBackground(t *testing.T) {
+	path, cleanup := tempLog(t, numberedLines(0, 50000))
+	defer cleanup()
+
+	logReader := NewLogReader(LogReaderConfig{Files: []LogFile{{LogFile: path}}, Seperator: " ", Headers: []Header{{Header: "Line"}, {Header: "Number"}}})
+	defer logReader.Close()
+	logReader.SetCapacity(2)
+	logReader.SetFilters([]Filter{filter(`9$`, false)})
+	logReader.Head()
+	select {
+	case <-logReader.Updates():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected the progress of the copy to be reported")
+	}
+
+	waitForFilters(&logReader)
+	expected := [][]string{{"line", "49989"}, {"line", "49999"}}
+	if result := *logReader.Tail(); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+}
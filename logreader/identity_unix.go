@@ -0,0 +1,16 @@
+//go:build !windows
+
+package logreader
+
+import (
+	"os"
+	"syscall"
+)
+
+//Returns the inode of the file, used to recognize a file that was replaced by another one with the same name
+func fileIdentity(fileInfo os.FileInfo) uint64 {
+	if stat, ok := fileInfo.Sys().(*syscall.Stat_t); ok {
+		return uint64(stat.Ino)
+	}
+	return 0
+}
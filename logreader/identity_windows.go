@@ -0,0 +1,8 @@
+package logreader
+
+import "os"
+
+//Windows has no inodes, files are recognized by their size and modification time only
+func fileIdentity(fileInfo os.FileInfo) uint64 {
+	return 0
+}
@@ -0,0 +1,116 @@
+package logreader
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//Parses JSON-lines logs, each header names the JSON path of the value that fills its column
+type jsonParser struct {
+	//Path segments for each configured header, for example "ctx.request_id" becomes ["ctx", "request_id"]
+	paths [][]string
+}
+
+func newJSONParser(headers []Header) jsonParser {
+	paths := make([][]string, len(headers))
+	for index, header := range headers {
+		paths[index] = strings.Split(header.field(), ".")
+	}
+
+	return jsonParser{paths}
+}
+
+func (p jsonParser) parse(line string) []string {
+	if line == "" {
+		return []string{}
+	}
+
+	object, ok := decodeJSONObject(line)
+	if !ok {
+		return []string{line}
+	}
+
+	columnValues := make([]string, len(p.paths))
+	for index, path := range p.paths {
+		columnValues[index] = jsonValueText(lookupJSONPath(object, path))
+	}
+
+	return columnValues
+}
+
+func (p jsonParser) isEntry(line string) bool {
+	_, ok := decodeJSONObject(line)
+	return ok
+}
+
+//Pretty prints the JSON object for the details view
+func (p jsonParser) formatDetails(entry string) string {
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, []byte(strings.TrimSpace(entry)), "", "  "); err != nil {
+		return entry
+	}
+
+	return indented.String()
+}
+
+//Decodes a line containing a single JSON object
+//Returns false if the line is not a JSON object
+func decodeJSONObject(line string) (map[string]interface{}, bool) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "{") {
+		return nil, false
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(trimmed))
+	decoder.UseNumber()
+	var object map[string]interface{}
+	if err := decoder.Decode(&object); err != nil {
+		return nil, false
+	}
+
+	return object, true
+}
+
+//Walks the path through nested objects and arrays
+//Returns nil if any segment of the path doesn't exist
+func lookupJSONPath(value interface{}, path []string) interface{} {
+	for _, segment := range path {
+		switch node := value.(type) {
+		case map[string]interface{}:
+			value = node[segment]
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil
+			}
+			value = node[index]
+		default:
+			return nil
+		}
+	}
+
+	return value
+}
+
+//Converts a decoded JSON value into the text displayed in a column
+func jsonValueText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case map[string]interface{}, []interface{}:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(encoded)
+	default:
+		return fmt.Sprint(v)
+	}
+}
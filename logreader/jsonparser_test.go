@@ -0,0 +1,64 @@
+package logreader
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func jsonReaderConfig() LogReaderConfig {
+	return LogReaderConfig{
+		Files:  []LogFile{{LogFile: "../test_logs/TestLogReader_Json.log", Name: "Name"}},
+		Format: "json",
+		Headers: []Header{
+			{Header: "Time", Size: 20, Field: "time"},
+			{Header: "level", Size: 5},
+			{Header: "Request", Size: 5, Field: "ctx.request_id"},
+			{Header: "Tag", Size: 5, Field: "tags.1"},
+			{Header: "Message", Size: -1, Field: "msg"},
+		},
+	}
+}
+
+func TestJSONParser_parse(t *testing.T) {
+	parser := newJSONParser(jsonReaderConfig().Headers)
+	expected := []string{"2010-11-11T10:00:03Z", "ERROR", "c3", "timeout", "Request failed"}
+	actual := parser.parse(`{"time":"2010-11-11T10:00:03Z","level":"ERROR","msg":"Request failed","ctx":{"request_id":"c3"},"tags":["db","timeout"]}`)
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %s, got %s", expected, actual)
+	}
+}
+
+func TestJSONParser_parse_numbersAndObjects(t *testing.T) {
+	parser := newJSONParser([]Header{{Header: "attempt", Field: "ctx.attempt"}, {Header: "ctx"}, {Header: "missing"}})
+	expected := []string{"10000000000000001", `{"attempt":10000000000000001}`, ""}
+	actual := parser.parse(`{"ctx":{"attempt":10000000000000001}}`)
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %s, got %s", expected, actual)
+	}
+}
+
+func TestJSONParser_nonJSONLineIsContinuation(t *testing.T) {
+	parser := newJSONParser(jsonReaderConfig().Headers)
+	line := "not a json line"
+
+	if parser.isEntry(line) {
+		t.Errorf("Expected %s to be a continuation line", line)
+	}
+	if actual := parser.parse(line); !reflect.DeepEqual(actual, []string{line}) {
+		t.Errorf("Expected a single column, got %s", actual)
+	}
+}
+
+func TestLogReader_Message_prettyPrintsJSON(t *testing.T) {
+	logReader := NewLogReader(jsonReaderConfig())
+	logReader.SetCapacity(4)
+	logReader.Tail()
+	actual := logReader.Message(4)
+
+	if !strings.Contains(actual, "\n  \"level\": \"ERROR\",\n") || !strings.Contains(actual, "\"request_id\": \"c3\"") {
+		t.Errorf("Expected the details to be pretty printed, got %s", actual)
+	}
+}
@@ -0,0 +1,342 @@
+package logreader
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/gob"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+//Number of lines between two checkpoints of the line index
+const lineIndexInterval = 1000
+
+//A sparse index of the byte offset of every Nth line of a log file, built in the background and updated as the file grows
+//The index of a regular file is saved in the cache directory and reused as long as the file wasn't replaced or rewritten
+type lineIndex struct {
+	mutex    sync.Mutex
+	interval int
+	//offsets[i] is the offset of the start of line i*interval, counting lines from 0
+	offsets []int64
+	//Number of complete lines indexed and the offset right after the last of them
+	lines   int
+	scanned int64
+	//Identity of the indexed file, a different inode, a rewrite of the same size or a different start invalidates the index
+	identity    uint64
+	size        int64
+	modTime     time.Time
+	fingerprint []byte
+	updating bool
+	//Where the index is saved, empty for logs that aren't regular files (streams, rotated families)
+	cachePath string
+}
+
+//The saved form of a line index
+type lineIndexFile struct {
+	Identity    uint64
+	Size        int64
+	ModTime     time.Time
+	Fingerprint []byte
+	Interval    int
+	Lines       int
+	Scanned     int64
+	Offsets     []int64
+}
+
+//Creates an empty index, loading the saved index of the file if it's still valid
+//The index isn't saved if cacheDirectory is empty
+func newLineIndex(path string, cacheDirectory string) *lineIndex {
+	index := &lineIndex{interval: lineIndexInterval, offsets: []int64{0}}
+	if cacheDirectory == "" {
+		return index
+	}
+
+	absolutePath, err := filepath.Abs(path)
+	if err != nil {
+		return index
+	}
+	hash := sha1.Sum([]byte(absolutePath))
+	index.cachePath = filepath.Join(cacheDirectory, hex.EncodeToString(hash[:])+".idx")
+	index.load(path)
+
+	return index
+}
+
+//Returns the default directory where the line indexes are saved
+func defaultIndexCacheDirectory() string {
+	cacheDirectory, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(cacheDirectory, "golog")
+}
+
+//Loads the saved index if it belongs to the same file and the file was only appended to since
+//A file emptied in place and written again (copytruncate) keeps its inode and may grow back past its old size, its first bytes tell it apart
+func (x *lineIndex) load(path string) {
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	cache, err := os.Open(x.cachePath)
+	if err != nil {
+		return
+	}
+	defer cache.Close()
+
+	var saved lineIndexFile
+	if err := gob.NewDecoder(cache).Decode(&saved); err != nil {
+		return
+	}
+	if saved.Identity != fileIdentity(fileInfo) || saved.Interval != x.interval || len(saved.Offsets) == 0 ||
+		fileInfo.Size() < saved.Size ||
+		(fileInfo.Size() == saved.Size && !fileInfo.ModTime().Equal(saved.ModTime)) ||
+		!sameStart(saved.Fingerprint, readFingerprint(path)) {
+		return
+	}
+
+	x.offsets = saved.Offsets
+	x.lines = saved.Lines
+	x.scanned = saved.Scanned
+	x.identity = saved.Identity
+	x.size = saved.Size
+	x.modTime = saved.ModTime
+	x.fingerprint = saved.Fingerprint
+}
+
+//Saves the index to the cache directory
+//Files shorter than the interval are quick to scan again and aren't saved
+func (x *lineIndex) save() {
+	x.mutex.Lock()
+	if x.cachePath == "" || len(x.offsets) < 2 {
+		x.mutex.Unlock()
+		return
+	}
+	saved := lineIndexFile{x.identity, x.size, x.modTime, x.fingerprint, x.interval, x.lines, x.scanned, append([]int64{}, x.offsets...)}
+	x.mutex.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(x.cachePath), 0755); err != nil {
+		return
+	}
+	temp := x.cachePath + ".tmp"
+	cache, err := os.Create(temp)
+	if err != nil {
+		return
+	}
+	err = gob.NewEncoder(cache).Encode(saved)
+	cache.Close()
+	if err != nil {
+		os.Remove(temp)
+		return
+	}
+	os.Rename(temp, x.cachePath)
+}
+
+//Starts indexing the lines added since the last update in the background
+//Starts over if the file was replaced or truncated, does nothing if an update is already running
+func (x *lineIndex) refresh(path string, open func() (logSource, error)) {
+	x.mutex.Lock()
+	if x.updating {
+		x.mutex.Unlock()
+		return
+	}
+	x.updating = true
+	x.mutex.Unlock()
+
+	go func() {
+		defer func() {
+			x.mutex.Lock()
+			x.updating = false
+			x.mutex.Unlock()
+		}()
+		if x.update(path, open) {
+			x.save()
+		}
+	}()
+}
+
+//Indexes the lines added since the last update
+//Starts over if the file was replaced, shrank or starts differently, see load
+//Returns true if the index changed
+func (x *lineIndex) update(path string, open func() (logSource, error)) bool {
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	fingerprint := readFingerprint(path)
+	source, err := open()
+	if err != nil {
+		return false
+	}
+	defer source.Close()
+	sourceInfo, err := source.Stat()
+	if err != nil {
+		return false
+	}
+
+	x.mutex.Lock()
+	reset := fileIdentity(fileInfo) != x.identity || sourceInfo.Size() < x.scanned || !sameStart(x.fingerprint, fingerprint)
+	if reset {
+		x.offsets = []int64{0}
+		x.lines = 0
+		x.scanned = 0
+	}
+	x.identity = fileIdentity(fileInfo)
+	x.fingerprint = fingerprint
+	x.size = fileInfo.Size()
+	x.modTime = fileInfo.ModTime()
+	lines, scanned := x.lines, x.scanned
+	x.mutex.Unlock()
+
+	if scanned == sourceInfo.Size() {
+		return reset
+	}
+
+	scannedLines := false
+	scanLines(source, scanned, lines, func(line int, offset int64) {
+		x.mutex.Lock()
+		defer x.mutex.Unlock()
+		x.lines = line
+		x.scanned = offset
+		if line%x.interval == 0 {
+			x.offsets = append(x.offsets, offset)
+		}
+		scannedLines = true
+	})
+
+	return reset || scannedLines
+}
+
+//Returns the closest indexed line at or before the line, and its offset
+func (x *lineIndex) checkpointBeforeLine(line int) (int, int64) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	checkpoint := line / x.interval
+	if checkpoint >= len(x.offsets) {
+		checkpoint = len(x.offsets) - 1
+	}
+	return checkpoint * x.interval, x.offsets[checkpoint]
+}
+
+//Returns the closest indexed line starting at or before the offset, and its offset
+func (x *lineIndex) checkpointBeforeOffset(offset int64) (int, int64) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	checkpoint := sort.Search(len(x.offsets), func(i int) bool {
+		return x.offsets[i] > offset
+	}) - 1
+	if checkpoint < 0 {
+		checkpoint = 0
+	}
+	return checkpoint * x.interval, x.offsets[checkpoint]
+}
+
+//Returns the offset of the last checkpoint before the offset
+//Returns false if the index didn't reach the offset yet
+func (x *lineIndex) checkpointBefore(offset int64) (int64, bool) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	if offset == 0 || x.scanned < offset {
+		return 0, false
+	}
+	checkpoint := sort.Search(len(x.offsets), func(i int) bool {
+		return x.offsets[i] >= offset
+	}) - 1
+	return x.offsets[checkpoint], true
+}
+
+//Returns the number of lines indexed so far and whether the whole file is indexed
+func (x *lineIndex) lineCount(size int64) (int, bool) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	return x.lines, x.scanned >= size
+}
+
+//Finds the offset of the start of the line (counting from 0) using the closest checkpoint
+//Returns the offset of the end of the file if it has fewer lines
+func (x *lineIndex) lineOffset(source logSource, line int) int64 {
+	checkpointLine, offset := x.checkpointBeforeLine(line)
+	if checkpointLine == line {
+		return offset
+	}
+
+	result := int64(-1)
+	end := scanLinesUntil(source, offset, checkpointLine, func(current int, lineOffset int64) bool {
+		if current == line {
+			result = lineOffset
+			return false
+		}
+		return true
+	})
+	if result == -1 {
+		return end
+	}
+	return result
+}
+
+//Counts the lines (from 0) before the offset using the closest checkpoint
+func (x *lineIndex) lineNumber(source logSource, offset int64) int {
+	checkpointLine, checkpointOffset := x.checkpointBeforeOffset(offset)
+	lines, _ := countNewLines(source, checkpointOffset, offset)
+	return checkpointLine + lines
+}
+
+//Reads the source from the offset of the line, calling visit with the number and offset of every following line start
+func scanLines(source logSource, offset int64, line int, visit func(line int, offset int64)) int64 {
+	return scanLinesUntil(source, offset, line, func(line int, offset int64) bool {
+		visit(line, offset)
+		return true
+	})
+}
+
+//Like scanLines but stops as soon as visit returns false
+//Returns the offset where the scan stopped
+func scanLinesUntil(source logSource, offset int64, line int, visit func(line int, offset int64) bool) int64 {
+	if _, err := source.Seek(offset, io.SeekStart); err != nil {
+		return offset
+	}
+
+	reader := bufio.NewReaderSize(source, 64*1024)
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		if len(chunk) > 0 && chunk[len(chunk)-1] == '\n' {
+			offset += int64(len(chunk))
+			line++
+			if !visit(line, offset) {
+				return offset
+			}
+		} else {
+			offset += int64(len(chunk))
+		}
+		if err != nil && err != bufio.ErrBufferFull {
+			return offset
+		}
+	}
+}
+
+//Drops the lines starting after the offset from the index, the file was cut back to the offset to be rewritten
+func (x *lineIndex) truncate(offset int64) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	if x.scanned <= offset {
+		return
+	}
+	checkpoint := sort.Search(len(x.offsets), func(i int) bool {
+		return x.offsets[i] > offset
+	}) - 1
+	if checkpoint < 0 {
+		checkpoint = 0
+	}
+	x.offsets = x.offsets[:checkpoint+1]
+	x.lines = checkpoint * x.interval
+	x.scanned = x.offsets[checkpoint]
+}
@@ -0,0 +1,222 @@
+package logreader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+//Writes lines "line N" numbered from 0
+func numberedLines(from int, to int) string {
+	var builder strings.Builder
+	for line := from; line < to; line++ {
+		fmt.Fprintf(&builder, "line %d\n", line)
+	}
+	return builder.String()
+}
+
+func openPlainFile(path string) func() (logSource, error) {
+	return func() (logSource, error) {
+		return os.Open(path)
+	}
+}
+
+func TestLineIndex_update(t *testing.T) {
+	content := numberedLines(0, 2500)
+	path, cleanup := tempLog(t, content)
+	defer cleanup()
+
+	index := newLineIndex(path, "")
+	index.update(path, openPlainFile(path))
+
+	if lines, complete := index.lineCount(int64(len(content))); lines != 2500 || !complete {
+		t.Errorf("Expected 2500 indexed lines, got %d (complete %v)", lines, complete)
+	}
+	if len(index.offsets) != 3 || index.offsets[1] != int64(strings.Index(content, "line 1000\n")) {
+		t.Errorf("Expected checkpoints at lines 0, 1000 and 2000, got %v", index.offsets)
+	}
+
+	file, _ := os.Open(path)
+	defer file.Close()
+	for _, line := range []int{0, 999, 1000, 1001, 2499} {
+		expected := int64(strings.Index(content, fmt.Sprintf("line %d\n", line)))
+		if offset := index.lineOffset(file, line); offset != expected {
+			t.Errorf("Expected line %d at offset %d, got %d", line, expected, offset)
+		}
+		if number := index.lineNumber(file, expected); number != line {
+			t.Errorf("Expected offset %d to be line %d, got %d", expected, line, number)
+		}
+	}
+	if offset := index.lineOffset(file, 5000); offset != int64(len(content)) {
+		t.Errorf("Expected a line past the end to be at the end of the file, got %d", offset)
+	}
+}
+
+func TestLineIndex_updateIncrementally(t *testing.T) {
+	path, cleanup := tempLog(t, numberedLines(0, 1500))
+	defer cleanup()
+
+	index := newLineIndex(path, "")
+	index.update(path, openPlainFile(path))
+
+	file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	file.WriteString(numberedLines(1500, 2100))
+	file.Close()
+	index.update(path, openPlainFile(path))
+
+	content := numberedLines(0, 2100)
+	if index.lines != 2100 || len(index.offsets) != 3 || index.offsets[2] != int64(strings.Index(content, "line 2000\n")) {
+		t.Errorf("Expected the appended lines to be indexed, got %d lines and checkpoints %v", index.lines, index.offsets)
+	}
+
+	//A truncated file is indexed from the start again
+	writeTestLog(t, path, numberedLines(0, 10))
+	index.update(path, openPlainFile(path))
+	if index.lines != 10 || len(index.offsets) != 1 {
+		t.Errorf("Expected the truncated file to be indexed again, got %d lines and checkpoints %v", index.lines, index.offsets)
+	}
+}
+
+func TestLineIndex_savedInCache(t *testing.T) {
+	path, cleanup := tempLog(t, numberedLines(0, 3000))
+	defer cleanup()
+	cacheDirectory := filepath.Join(filepath.Dir(path), "cache")
+
+	index := newLineIndex(path, cacheDirectory)
+	index.update(path, openPlainFile(path))
+	index.save()
+
+	loaded := newLineIndex(path, cacheDirectory)
+	if loaded.lines != 3000 || len(loaded.offsets) != 4 || loaded.offsets[3] != index.offsets[3] {
+		t.Errorf("Expected the saved index to be loaded, got %d lines and checkpoints %v", loaded.lines, loaded.offsets)
+	}
+
+	//A file replaced by another one isn't matched with the saved index
+	os.Remove(path)
+	writeTestLog(t, path, numberedLines(0, 3000))
+	if replaced := newLineIndex(path, cacheDirectory); replaced.lines != 0 {
+		t.Errorf("Expected the index of a replaced file to be discarded, got %d lines", replaced.lines)
+	}
+}
+
+func TestLineIndex_copytruncateGrownBack(t *testing.T) {
+	path, cleanup := tempLog(t, numberedLines(0, 3000))
+	defer cleanup()
+	cacheDirectory := filepath.Join(filepath.Dir(path), "cache")
+
+	index := newLineIndex(path, cacheDirectory)
+	index.update(path, openPlainFile(path))
+	index.save()
+
+	//The file is emptied in place and written past its old size before it's read again
+	content := strings.Replace(numberedLines(0, 4000), "line", "entry", -1)
+	writeTestLog(t, path, content)
+	if loaded := newLineIndex(path, cacheDirectory); loaded.lines != 0 {
+		t.Errorf("Expected the index of a file written again to be discarded, got %d lines", loaded.lines)
+	}
+	index.update(path, openPlainFile(path))
+	if index.lines != 4000 || index.offsets[1] != int64(strings.Index(content, "entry 1000\n")) {
+		t.Errorf("Expected the file written again to be indexed from the start, got %d lines and checkpoints %v", index.lines, index.offsets)
+	}
+}
+
+func TestLineIndex_readsBackFromCheckpoints(t *testing.T) {
+	content := numberedLines(0, 2500)
+	path, cleanup := tempLog(t, content)
+	defer cleanup()
+	file, _ := os.Open(path)
+	defer file.Close()
+
+	index := newLineIndex(path, "")
+	index.update(path, openPlainFile(path))
+	lines := newBackwardLines(file, len(content), index)
+	for expected := 2499; expected >= 0; expected-- {
+		line, start, ok := lines.previous()
+		if !ok || line != fmt.Sprintf("line %d", expected) || start != strings.Index(content, line+"\n") {
+			t.Fatalf("Expected line %d, got %q at %d (%v)", expected, line, start, ok)
+		}
+	}
+	if _, _, ok := lines.previous(); ok {
+		t.Errorf("Expected no line before the first one")
+	}
+}
+
+func numberedLogReader(t *testing.T, lines int) (LogReader, func()) {
+	path, cleanup := tempLog(t, numberedLines(0, lines))
+	config := logreaderConfig(path, []int{10, 10, 10})
+	config.IndexCacheDir = filepath.Join(filepath.Dir(path), "cache")
+	logReader := NewLogReader(config)
+	logReader.SetCapacity(2)
+	return logReader, cleanup
+}
+
+func TestLogReader_SeekLine(t *testing.T) {
+	logReader, cleanup := numberedLogReader(t, 2500)
+	defer cleanup()
+
+	expected := [][]string{{"line 1233"}, {"line 1234"}}
+	if result := *logReader.SeekLine(1234); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+	if line := logReader.CurrentLine(); line != 1234 {
+		t.Errorf("Expected the page to start at line 1234, got %d", line)
+	}
+
+	expected = [][]string{{"line 2498"}, {"line 2499"}}
+	if result := *logReader.SeekLine(10000); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected the last page %s got %s`, expected, result)
+	}
+}
+
+func TestLogReader_SeekPercent(t *testing.T) {
+	//Lines 0 to 9 are 7 bytes long and the others 8, so the middle of the 790 bytes falls inside line 50
+	logReader, cleanup := numberedLogReader(t, 100)
+	defer cleanup()
+
+	expected := [][]string{{"line 51"}, {"line 52"}}
+	if result := *logReader.SeekPercent(50); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+
+	expected = [][]string{{"line 0"}, {"line 1"}}
+	if result := *logReader.SeekPercent(0); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+}
+
+func TestLogReader_SeekRelative(t *testing.T) {
+	logReader, cleanup := numberedLogReader(t, 2500)
+	defer cleanup()
+	logReader.SeekLine(1000)
+
+	expected := [][]string{{"line 1199"}, {"line 1200"}}
+	if result := *logReader.SeekRelative(200); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+	expected = [][]string{{"line 999"}, {"line 1000"}}
+	if result := *logReader.SeekRelative(-200); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+}
+
+func TestLineIndex_update_reportsOnlyChanges(t *testing.T) {
+	path, cleanup := tempLog(t, numberedLines(0, 10))
+	defer cleanup()
+
+	index := newLineIndex(path, "")
+	if !index.update(path, openPlainFile(path)) {
+		t.Errorf("Expected the first update to change the index")
+	}
+	if index.update(path, openPlainFile(path)) {
+		t.Errorf("Expected an update of an unchanged file not to change the index")
+	}
+	file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	file.WriteString("line 10\n")
+	file.Close()
+	if !index.update(path, openPlainFile(path)) {
+		t.Errorf("Expected the appended line to change the index")
+	}
+}
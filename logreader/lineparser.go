@@ -0,0 +1,167 @@
+package logreader
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+//Splits a single log line into its columns
+type lineParser interface {
+	//Returns the columns of the line, continuation lines (stack traces etc.) are returned as a single column
+	parse(line string) []string
+	//Returns true if the line starts a new log entry, false if it is a continuation of the previous one
+	isEntry(line string) bool
+}
+
+//Implemented by parsers that render a log entry differently in the details view
+type detailsFormatter interface {
+	formatDetails(entry string) string
+}
+
+//Implemented by parsers that add columns after the configured headers
+type extraColumns interface {
+	extraHeaders() []Header
+}
+
+//Implemented by parsers whose lines carry more than the log text, such as the file index of the merged view
+type lineText interface {
+	text(line string) string
+}
+
+//Returns the log text of a line as it was written to the log file
+func logText(parser lineParser, line string) string {
+	if withText, ok := parser.(lineText); ok {
+		return withText.text(line)
+	}
+	return line
+}
+
+//Splits lines on the configured separator string
+type separatorParser struct {
+	seperator string
+}
+
+//Matches lines against a regular expression, each named capture group fills the column of the header with the same name
+type patternParser struct {
+	pattern *regexp.Regexp
+	//Index of the capture group for each configured header, -1 if the pattern has no group for that header
+	groups []int
+}
+
+//Decides which lines start an entry with the configured regular expression instead of the format, the rest is left to the format
+type entryStartParser struct {
+	lineParser
+	entryStart *regexp.Regexp
+}
+
+//Creates the line parser described by the configuration
+//Returns an error if the format is unknown or the configured pattern or entry start cannot be compiled
+func newLineParser(config LogReaderConfig) (lineParser, error) {
+	parser, err := newFormatParser(config)
+	if err != nil || config.EntryStart == "" {
+		return parser, err
+	}
+
+	entryStart, err := regexp.Compile(config.EntryStart)
+	if err != nil {
+		return nil, fmt.Errorf("invalid entry start %q: %v", config.EntryStart, err)
+	}
+	return entryStartParser{parser, entryStart}, nil
+}
+
+//Creates the parser of the configured separator, pattern or format
+func newFormatParser(config LogReaderConfig) (lineParser, error) {
+	switch {
+	case config.Format == "json":
+		return newJSONParser(config.Headers), nil
+	case config.Format == "logfmt":
+		return newLogfmtParser(config.Headers), nil
+	case config.Format != "":
+		return nil, fmt.Errorf("unknown format %q", config.Format)
+	case config.Pattern != "":
+		return newPatternParser(config.Pattern, config.Headers)
+	}
+
+	return separatorParser{config.Seperator}, nil
+}
+
+func (p separatorParser) parse(line string) []string {
+	//Without a separator, for example when piping a log in without a configuration, the line is a single column
+	if p.seperator == "" {
+		if line == "" {
+			return []string{}
+		}
+		return []string{line}
+	}
+	return parseLine(line, p.seperator)
+}
+
+func (p separatorParser) isEntry(line string) bool {
+	return strings.Contains(line, p.seperator)
+}
+
+//Compiles the pattern and maps its named capture groups onto the headers
+//Returns an error if the pattern is not a valid regular expression or has no named groups
+func newPatternParser(pattern string, headers []Header) (patternParser, error) {
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return patternParser{}, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+	}
+
+	groups := make([]int, len(headers))
+	namedGroups := 0
+	for index, header := range headers {
+		groups[index] = compiled.SubexpIndex(header.Header)
+		if groups[index] != -1 {
+			namedGroups++
+		}
+	}
+	if namedGroups == 0 {
+		return patternParser{}, fmt.Errorf("pattern %q has no named groups matching the configured headers", pattern)
+	}
+
+	return patternParser{compiled, groups}, nil
+}
+
+func (p patternParser) parse(line string) []string {
+	if line == "" {
+		return []string{}
+	}
+
+	match := p.pattern.FindStringSubmatch(line)
+	if match == nil {
+		return []string{line}
+	}
+
+	columnValues := make([]string, len(p.groups))
+	for index, group := range p.groups {
+		if group != -1 {
+			columnValues[index] = match[group]
+		}
+	}
+
+	return columnValues
+}
+
+func (p patternParser) isEntry(line string) bool {
+	return p.pattern.MatchString(line)
+}
+
+func (p entryStartParser) isEntry(line string) bool {
+	return p.entryStart.MatchString(line)
+}
+
+func (p entryStartParser) formatDetails(entry string) string {
+	if formatter, ok := p.lineParser.(detailsFormatter); ok {
+		return formatter.formatDetails(entry)
+	}
+	return entry
+}
+
+func (p entryStartParser) extraHeaders() []Header {
+	if extra, ok := p.lineParser.(extraColumns); ok {
+		return extra.extraHeaders()
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package logreader
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testPattern = `^(?P<Date>\S+ \S+) \[(?P<Thread>[^\]]+)\] (?P<Severity>\w+)\s+(?P<Package>\S+) - (?P<Message>.*)$`
+
+func patternHeaders() []Header {
+	return []Header{
+		{Header: "Date", Size: 19},
+		{Header: "Thread", Size: 10},
+		{Header: "Severity", Size: 5},
+		{Header: "Package", Size: 20},
+		{Header: "Message", Size: -1},
+	}
+}
+
+func TestLineParser_patternParser_namedGroupsMapToHeaders(t *testing.T) {
+	parser, err := newPatternParser(testPattern, patternHeaders())
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	expected := []string{"2010-11-11 10:00:02", "Thread-2", "WARN", "com.test.Config", "Missing property, using default value"}
+	actual := parser.parse("2010-11-11 10:00:02 [Thread-2] WARN  com.test.Config - Missing property, using default value")
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %s, got %s", expected, actual)
+	}
+}
+
+func TestLineParser_patternParser_headerWithoutGroupIsEmpty(t *testing.T) {
+	headers := append(patternHeaders(), Header{Header: "Host", Size: 10})
+	parser, _ := newPatternParser(testPattern, headers)
+	actual := parser.parse("2010-11-11 10:00:02 [Thread-2] WARN  com.test.Config - Message")
+
+	if len(actual) != len(headers) || actual[len(actual)-1] != "" {
+		t.Errorf("Expected an empty trailing column, got %q", actual)
+	}
+}
+
+func TestLineParser_patternParser_nonMatchingLineIsContinuation(t *testing.T) {
+	parser, _ := newPatternParser(testPattern, patternHeaders())
+	line := "       at com.test.Service.call(Service.java:42)"
+	expected := []string{line}
+
+	if actual := parser.parse(line); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %s, got %s", expected, actual)
+	}
+	if parser.isEntry(line) {
+		t.Errorf("Expected %s to be a continuation line", line)
+	}
+}
+
+func TestLineParser_patternParser_invalidPattern(t *testing.T) {
+	if _, err := newPatternParser(`(?P<Date>[`, patternHeaders()); err == nil {
+		t.Errorf("Expected an error for an invalid pattern")
+	}
+	if _, err := newPatternParser(`^(\S+) (\S+)$`, patternHeaders()); err == nil {
+		t.Errorf("Expected an error for a pattern without named groups")
+	}
+}
+
+func TestLineParser_separatorParser(t *testing.T) {
+	parser := separatorParser{"~"}
+	if !parser.isEntry("11/11/2010~Thread-1~com.test") {
+		t.Errorf("Expected a line containing the separator to be an entry")
+	}
+	if parser.isEntry("       at rx.Observable$31.onError(Observable.java:7204)") {
+		t.Errorf("Expected a line without the separator to be a continuation line")
+	}
+}
+
+func TestLogReader_Tail_withPattern(t *testing.T) {
+	config := LogReaderConfig{
+		Files:   []LogFile{{LogFile: "../test_logs/TestLogReader_Pattern.log", Name: "Name"}},
+		Pattern: testPattern,
+		Headers: patternHeaders(),
+	}
+	//The page ends with whole entries, the stack trace is shown with the entry it belongs to
+	expected := [][]string{
+		{"2010-11-11 10:00:03", "Thread-3", "ERROR", "com.test.Service", "Request failed"},
+		{"java.lang.IllegalStateException: Connection closed"},
+		{"       at com.test.Service.call(Service.java:42)"},
+		{"2010-11-11 10:00:04", "Thread-4", "INFO", "com.test.Main", "Shutting down"},
+	}
+
+	logReader := NewLogReader(config)
+	logReader.SetCapacity(4)
+	result := *logReader.Tail()
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+}
@@ -0,0 +1,157 @@
+package logreader
+
+import (
+	"strconv"
+	"strings"
+)
+
+//Name of the trailing column holding the logfmt keys that aren't mapped to a header
+const extraHeader = "Extra"
+
+//Parses logfmt (key=value) logs, each header names the key that fills its column
+//Keys that aren't mapped to any header are collected in a trailing extra column
+type logfmtParser struct {
+	columns map[string]int
+	headers int
+}
+
+//A single key=value pair of a logfmt line
+type logfmtPair struct {
+	key   string
+	value string
+}
+
+func newLogfmtParser(headers []Header) logfmtParser {
+	columns := make(map[string]int, len(headers))
+	for index, header := range headers {
+		columns[header.field()] = index
+	}
+
+	return logfmtParser{columns, len(headers)}
+}
+
+func (p logfmtParser) parse(line string) []string {
+	if line == "" {
+		return []string{}
+	}
+
+	pairs, ok := splitLogfmt(line)
+	if !ok {
+		return []string{line}
+	}
+
+	columnValues := make([]string, p.headers+1)
+	var extra []string
+	for _, pair := range pairs {
+		if index, found := p.columns[pair.key]; found {
+			columnValues[index] = pair.value
+		} else {
+			extra = append(extra, pair.String())
+		}
+	}
+	columnValues[p.headers] = strings.Join(extra, " ")
+
+	return columnValues
+}
+
+func (p logfmtParser) isEntry(line string) bool {
+	_, ok := splitLogfmt(line)
+	return ok
+}
+
+//Adds the column holding the unmapped keys after the configured headers
+func (p logfmtParser) extraHeaders() []Header {
+	return []Header{{Header: extraHeader, Size: -1}}
+}
+
+//Formats the pair back to logfmt, quoting the value if needed
+func (p logfmtPair) String() string {
+	if p.value == "" {
+		return p.key
+	}
+	if strings.ContainsAny(p.value, " =\"\\\t\n") {
+		return p.key + "=" + strconv.Quote(p.value)
+	}
+	return p.key + "=" + p.value
+}
+
+//Splits a logfmt line into its key=value pairs, values may be double quoted and contain backslash escapes
+//Returns false if the line doesn't start with a key=value pair, which makes it a continuation line
+func splitLogfmt(line string) ([]logfmtPair, bool) {
+	var pairs []logfmtPair
+	position := 0
+	for {
+		for position < len(line) && line[position] == ' ' {
+			position++
+		}
+		if position >= len(line) {
+			break
+		}
+
+		keyStart := position
+		for position < len(line) && line[position] != '=' && line[position] != ' ' && line[position] != '"' {
+			position++
+		}
+		key := line[keyStart:position]
+		if key == "" || position >= len(line) || line[position] != '=' {
+			if len(pairs) == 0 {
+				return nil, false
+			}
+			//A bare key without a value
+			if key != "" {
+				pairs = append(pairs, logfmtPair{key, ""})
+			} else {
+				position++
+			}
+			continue
+		}
+		//Skip the '='
+		position++
+
+		var value string
+		if position < len(line) && line[position] == '"' {
+			value, position = readQuotedLogfmtValue(line, position+1)
+		} else {
+			valueStart := position
+			for position < len(line) && line[position] != ' ' {
+				position++
+			}
+			value = line[valueStart:position]
+		}
+		pairs = append(pairs, logfmtPair{key, value})
+	}
+
+	return pairs, len(pairs) > 0
+}
+
+//Reads a quoted value starting after the opening quote, resolving the backslash escapes
+//Returns the unescaped value and the position after the closing quote
+func readQuotedLogfmtValue(line string, position int) (string, int) {
+	var value strings.Builder
+	for position < len(line) {
+		c := line[position]
+		position++
+		switch {
+		case c == '"':
+			return value.String(), position
+		case c == '\\' && position < len(line):
+			escaped := line[position]
+			position++
+			switch escaped {
+			case 'n':
+				value.WriteByte('\n')
+			case 't':
+				value.WriteByte('\t')
+			case 'r':
+				value.WriteByte('\r')
+			default:
+				value.WriteByte(escaped)
+			}
+		default:
+			value.WriteByte(c)
+		}
+	}
+
+	//Unterminated quote, the value runs until the end of the line
+	return value.String(), position
+}
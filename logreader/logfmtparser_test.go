@@ -0,0 +1,85 @@
+package logreader
+
+import (
+	"reflect"
+	"testing"
+)
+
+func logfmtHeaders() []Header {
+	return []Header{
+		{Header: "Time", Size: 20, Field: "ts"},
+		{Header: "Severity", Size: 5, Field: "level"},
+		{Header: "Message", Size: 30, Field: "msg"},
+	}
+}
+
+func TestLogfmtParser_parse_quotedValuesAndExtraColumn(t *testing.T) {
+	parser := newLogfmtParser(logfmtHeaders())
+	expected := []string{"2010-11-11T10:00:03Z", "error", "Request failed", `err="connection reset by peer" request_id=c3`}
+	actual := parser.parse(`ts=2010-11-11T10:00:03Z level=error msg="Request failed" err="connection reset by peer" request_id=c3`)
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+func TestLogfmtParser_parse_escapes(t *testing.T) {
+	parser := newLogfmtParser(logfmtHeaders())
+	expected := []string{"", "warn", "Slow \"db\"\tcall\\", "debug"}
+	actual := parser.parse(`level=warn msg="Slow \"db\"\tcall\\" debug`)
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+func TestLogfmtParser_parse_unterminatedQuote(t *testing.T) {
+	parser := newLogfmtParser(logfmtHeaders())
+	expected := []string{"", "info", "runs until the end", ""}
+	actual := parser.parse(`level=info msg="runs until the end`)
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+func TestLogfmtParser_continuationLine(t *testing.T) {
+	parser := newLogfmtParser(logfmtHeaders())
+	for _, line := range []string{"goroutine 1 [running]:", "       at com.test.Main(Main.java:10)", "panic: x=1"} {
+		if parser.isEntry(line) {
+			t.Errorf("Expected %s to be a continuation line", line)
+		}
+	}
+}
+
+func TestLogReader_GetHeaders_logfmtAddsExtraColumn(t *testing.T) {
+	logReader := NewLogReader(LogReaderConfig{Format: "logfmt", Headers: logfmtHeaders()})
+	expected := []string{"Time", "Severity", "Message", "Extra"}
+
+	if !reflect.DeepEqual(logReader.GetHeaders(), expected) {
+		t.Errorf("Expected %s, got %s", expected, logReader.GetHeaders())
+	}
+	if sizes := logReader.GetColumnSizes(); len(sizes) != 4 || sizes[3] != -1 {
+		t.Errorf("Expected the extra column to fill the rest of the line, got %v", sizes)
+	}
+}
+
+func TestLogReader_Tail_logfmt(t *testing.T) {
+	config := LogReaderConfig{
+		Files:   []LogFile{{LogFile: "../test_logs/TestLogReader_Logfmt.log", Name: "Name"}},
+		Format:  "logfmt",
+		Headers: logfmtHeaders(),
+	}
+	expected := [][]string{
+		{"2010-11-11T10:00:01Z", "info", "Starting application", "version=1.2"},
+		{"2010-11-11T10:00:02Z", "warn", `Slow response from "db"`, "took=1.5s"},
+	}
+
+	logReader := NewLogReader(config)
+	logReader.SetCapacity(2)
+	result := *logReader.Head()
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %q got %q`, expected, result)
+	}
+}
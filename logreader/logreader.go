@@ -3,45 +3,287 @@ package logreader
 import (
 	"os"
 	"strings"
-	"bufio"
 	"fmt"
+	"errors"
+	"time"
 )
 
 type LogReaderConfig struct {
 	AutoDetectFiles     string `yaml:"autoDetectFiles"`
 	Files               []LogFile `yaml:files`
 	Seperator           string `yaml:"seperator"`
+	Pattern             string `yaml:"pattern"`
+	Format              string `yaml:"format"`
 	Headers             []Header
+	//The header of the column holding the time of each entry and its layout, either a Go layout (2006-01-02 15:04:05), a strftime layout (%Y-%m-%d %H:%M:%S) or auto
+	TimestampColumn     string `yaml:"timestampColumn"`
+	TimestampLayout     string `yaml:"timestampLayout"`
+	//A regex matching the lines that start a log entry, the other lines continue the entry before them, defaults to the lines the format can parse
+	EntryStart          string `yaml:"entryStart"`
+	//Where the line indexes of large files are saved, defaults to golog in the user cache directory
+	IndexCacheDir       string `yaml:"indexCacheDir"`
 }
 
 type Header struct {
 	Header string `yaml:"header"`
 	Size int `yaml:"size"`
+	Field string `yaml:"field"`
 }
 
+//A log file to read, the parsing settings override the top level ones for this file only
+//The rotation glob (app.log*) matches the rotated siblings that are read together with the file as one stream
 type LogFile struct {
-	LogFile string `yaml:"file"`
-	Name    string `yaml:"name"`
+	LogFile   string `yaml:"file"`
+	Name      string `yaml:"name"`
+	RotationGlob string `yaml:"rotationGlob"`
+	Seperator string `yaml:"seperator"`
+	Pattern   string `yaml:"pattern"`
+	Format    string `yaml:"format"`
+	Headers   []Header `yaml:"headers"`
+	TimestampColumn string `yaml:"timestampColumn"`
+	TimestampLayout string `yaml:"timestampLayout"`
+	EntryStart string `yaml:"entryStart"`
 }
 
 type LogReader struct {
 	FileIndex     int
 	config        LogReaderConfig
+	//The offset of the first line of the page of each file and the offset right after its last line
+	pageStart     []int
 	currentOffset []int
 	Capacity      int
-	currentLoadedPage *[][]string
-	previousReadFileInfo os.FileInfo
+	tailStates    []tailState
+	unseen        []unseenLines
+	spools        []*streamSpool
+	parsers       []lineParser
+	defaultParser lineParser
+	decompressed  *decompressionCache
+	indexes       []*lineIndex
+	timestamps    []*timestampParser
+	merged        *mergedSpool
+	filters       [][]Filter
+	filtered      []*filteredView
+	collapsed     bool
+	matches       []*matchCache
+	updates       chan struct{}
 }
 
+//How often the views copied in the background report their progress, see Updates
+const updateInterval = 200 * time.Millisecond
+
+//Returns a new instance of a LogReader
+//Falls back to splitting lines on the separator if the configured pattern is invalid, use Validate to report it
 func NewLogReader(config LogReaderConfig) LogReader {
 	var l LogReader
 	l.config = config
-	l.currentOffset = make([]int, len(l.config.Files))
+	//The slices indexed by file have an extra slot for the merged view, see MergedIndex
+	files := len(l.config.Files)
+	l.pageStart = make([]int, files+1)
+	l.currentOffset = make([]int, files+1)
+	l.tailStates = make([]tailState, files+1)
+	l.spools = make([]*streamSpool, files+1)
+	for index, file := range l.config.Files {
+		if isStream(file.LogFile) {
+			//The file is reported as missing if the spool can't be created
+			l.spools[index], _ = startSpool(file.LogFile)
+		}
+	}
+	l.unseen = make([]unseenLines, files+1)
+	for index := range l.unseen {
+		l.unseen[index].offset = -1
+	}
+	l.updates = make(chan struct{}, 1)
+	l.decompressed = newDecompressionCache(l.notify)
+	l.defaultParser = newLineParserOrSeparator(config)
+	l.parsers = make([]lineParser, files+1)
+	l.timestamps = make([]*timestampParser, files+1)
+	names := make([]string, files)
+	headers := make([][]Header, files)
+	for index, file := range l.config.Files {
+		l.parsers[index] = newLineParserOrSeparator(config.fileConfig(index))
+		//Files with an invalid timestamp configuration can't be navigated by time, use Validate to report it
+		l.timestamps[index], _ = newTimestampParser(config.fileConfig(index))
+		names[index] = file.Name
+		headers[index] = config.fileConfig(index).Headers
+		if extra, ok := l.parsers[index].(extraColumns); ok {
+			headers[index] = append(append([]Header{}, headers[index]...), extra.extraHeaders()...)
+		}
+	}
+	l.parsers[files] = newMergedParser(names, l.parsers[:files], headers)
+	l.merged = newMergedSpool(files)
+	cacheDirectory := config.IndexCacheDir
+	if cacheDirectory == "" {
+		cacheDirectory = defaultIndexCacheDirectory()
+	}
+	l.indexes = make([]*lineIndex, files+1)
+	for index, file := range l.config.Files {
+		//Only regular files keep their identity between runs, streams and rotated families are indexed in memory
+		if l.spools[index] != nil || file.RotationGlob != "" {
+			l.indexes[index] = newLineIndex(file.LogFile, "")
+		} else {
+			l.indexes[index] = newLineIndex(file.LogFile, cacheDirectory)
+		}
+	}
+	l.indexes[files] = newLineIndex("", "")
+	l.filters = make([][]Filter, files+1)
+	l.filtered = make([]*filteredView, files+1)
+	for index := range l.filtered {
+		l.filtered[index] = newFilteredView()
+	}
+	l.matches = make([]*matchCache, files+1)
+	for index := range l.matches {
+		l.matches[index] = &matchCache{}
+	}
 
 	return l
 }
 
-//Reads the log file from the current offset
+func newLineParserOrSeparator(config LogReaderConfig) lineParser {
+	parser, err := newLineParser(config)
+	if err != nil {
+		return separatorParser{config.Seperator}
+	}
+	return parser
+}
+
+//Returns the parsing configuration of the file at the index
+//The file's separator, pattern and format replace the top level ones together if any of them is set, its headers replace the top level headers if set
+//The file's timestamp column replaces the top level one together with its layout, its entry start replaces the top level one
+func (c LogReaderConfig) fileConfig(index int) LogReaderConfig {
+	if index < 0 || index >= len(c.Files) {
+		return c
+	}
+
+	file := c.Files[index]
+	if file.Seperator != "" || file.Pattern != "" || file.Format != "" {
+		c.Seperator = file.Seperator
+		c.Pattern = file.Pattern
+		c.Format = file.Format
+	}
+	if len(file.Headers) > 0 {
+		c.Headers = file.Headers
+	}
+	if file.TimestampColumn != "" {
+		c.TimestampColumn = file.TimestampColumn
+		c.TimestampLayout = file.TimestampLayout
+	}
+	if file.EntryStart != "" {
+		c.EntryStart = file.EntryStart
+	}
+
+	return c
+}
+
+//Returns the line parser of the active file
+func (l LogReader) parser() lineParser {
+	return l.parserOf(l.FileIndex)
+}
+
+//Returns the parser of the rows of the file at the index, the parser of whole records while collapsed
+func (l LogReader) rowParser(index int) lineParser {
+	if l.collapsed {
+		return recordParser{l.parserOf(index)}
+	}
+	return l.parserOf(index)
+}
+
+//Returns the line parser of the file at the index
+func (l LogReader) parserOf(index int) lineParser {
+	if index < len(l.config.Files) || l.isMerged(index) {
+		return l.parsers[index]
+	}
+	return l.defaultParser
+}
+
+//Returns the timestamp parser of the file at the index, nil if it has no timestamp column
+//A layout that isn't configured is detected from the start of the file once it has a timestamp, the parser is replaced rather than changed
+func (l LogReader) timestampsOf(index int) *timestampParser {
+	timestamps := l.timestamps[index]
+	if timestamps == nil || timestamps.layout != "" {
+		return timestamps
+	}
+	file, err := l.openFile(index)
+	if err != nil {
+		return timestamps
+	}
+	defer file.Close()
+
+	if layout := detectLayout(file, l.parserOf(index), *timestamps, layoutDetectionLines); layout != "" {
+		l.timestamps[index] = &timestampParser{timestamps.column, layout}
+	}
+	return l.timestamps[index]
+}
+
+//Returns the file index of the merged view, set FileIndex to it to read all files interleaved by the time of their entries
+//The entries of files without a timestamp column are placed after the last timed entry read before them
+func (l LogReader) MergedIndex() int {
+	return len(l.config.Files)
+}
+
+//Returns true if the index is the merged view of the files
+func (l LogReader) isMerged(index int) bool {
+	return len(l.config.Files) > 0 && index == len(l.config.Files)
+}
+
+//Merges the lines added to the files since the last update into the merged view
+//Views of the merged copy start over from where entries older than the last ones merged were inserted
+func (l LogReader) updateMerged() error {
+	paths := make([]string, len(l.config.Files))
+	timestamps := make([]*timestampParser, len(l.config.Files))
+	for index := range l.config.Files {
+		paths[index] = l.filePath(index)
+		timestamps[index] = l.timestampsOf(index)
+	}
+	rewritten, err := l.merged.update(paths, l.openFile, l.parsers, timestamps)
+	if err != nil || rewritten < 0 {
+		return err
+	}
+	index := l.MergedIndex()
+	l.indexes[index].truncate(rewritten)
+	l.matches[index].reset()
+	if len(l.filters[index]) > 0 {
+		l.resetView(index)
+	}
+	if l.pageStart[index] > int(rewritten) {
+		l.pageStart[index] = int(rewritten)
+	}
+	return nil
+}
+
+//Returns the name of the field the column is read from, defaults to the header text
+func (h Header) field() string {
+	if h.Field != "" {
+		return h.Field
+	}
+	return h.Header
+}
+
+//Checks that the parsing configuration can be used to read the log files
+//The top level settings are only checked through the files inheriting them, or on their own if there are no files
+//Returns an error describing the first invalid setting
+func (c LogReaderConfig) Validate() error {
+	if len(c.Files) == 0 {
+		if _, err := newLineParser(c); err != nil {
+			return err
+		}
+		if _, err := newTimestampParser(c); err != nil {
+			return err
+		}
+	}
+
+	for index, file := range c.Files {
+		if _, err := newLineParser(c.fileConfig(index)); err != nil {
+			return fmt.Errorf("file %s: %v", file.LogFile, err)
+		}
+		if _, err := newTimestampParser(c.fileConfig(index)); err != nil {
+			return fmt.Errorf("file %s: %v", file.LogFile, err)
+		}
+	}
+
+	return nil
+}
+
+//Reads the current page again from its first line, a page that wasn't full gets the records added since
 //Returns a two dimensional slice containing the parsed rows
 func (l *LogReader) Refresh() *[][]string {
 	file, err := l.openLogFile()
@@ -50,48 +292,96 @@ func (l *LogReader) Refresh() *[][]string {
 	}
 	defer file.Close()
 
-	data, offset := readLogFileFromOffsetUp(file, l.config.Seperator, l.Capacity, l.currentOffset[l.FileIndex] + l.Capacity)
-	l.currentOffset[l.FileIndex] = offset
-	return data
+	return l.showPage(file, l.pageStart[l.FileIndex])
 }
 
+//Reads the page of whole records starting at the offset and makes it the current page, the last page if it reaches the end of the file
+//Returns a two dimensional slice containing the parsed rows
+func (l *LogReader) showPage(file logSource, start int) *[][]string {
+	rows, end := readPage(file, l.parser(), l.Capacity, l.collapsed, start)
+	if fileInfo, err := file.Stat(); err == nil && end >= int(fileInfo.Size()) {
+		start = pageStartBefore(file, l.parser(), l.viewIndex(l.FileIndex), l.Capacity, l.collapsed, int(fileInfo.Size()))
+		rows, end = readPage(file, l.parser(), l.Capacity, l.collapsed, start)
+	}
+	l.pageStart[l.FileIndex] = start
+	l.currentOffset[l.FileIndex] = end
+	return &rows
+}
 
-//Reads the last N lines where N=The capacity configuration value
+//Reads the last page of whole records, as many as fit in N lines where N=The capacity configuration value, or N records while collapsed
+//Detects if the file was truncated or rotated since the last tail, see RotationNotice
 //Returns a two dimensional slice containing the parsed rows
 func (l *LogReader) Tail() *[][]string {
-	fileInfo, err := os.Stat(l.config.Files[l.FileIndex].LogFile)
+	if l.isMerged(l.FileIndex) {
+		l.updateMerged()
+	}
+	if len(l.filters[l.FileIndex]) > 0 {
+		l.updateView(l.FileIndex)
+	}
+	path := l.viewPath(l.FileIndex)
+	fileInfo, err := os.Stat(path)
 	if err != nil {
 		return &[][]string{}
 	}
-	//Check if any changes happened to the file, otherwise return last page
-	if l.previousReadFileInfo != nil &&
-		fileInfo.Size() == l.previousReadFileInfo.Size() &&
-		fileInfo.ModTime() == l.previousReadFileInfo.ModTime() &&
-		l.currentLoadedPage != nil {
+	//Compressed files are tailed by their decompressed copy, it grows while it is decompressed
+	if copyInfo, ok := l.decompressed.stat(path); ok {
+		fileInfo = copyInfo
+	}
+	state := &l.tailStates[l.FileIndex]
+	fingerprint := readFingerprint(path)
 
-		l.currentOffset[l.FileIndex] = int(fileInfo.Size())
-		return l.currentLoadedPage
+	//The copies the reader writes are rewritten in place by the reader itself, only the log files are rotated or truncated
+	if notice := state.detectRotation(fileInfo, fingerprint); notice != "" && !l.ownsView(l.FileIndex) {
+		state.notice = notice
+	} else if state.unchanged(fileInfo) {
+		//No changes happened to the file, return the last page
+		l.pageStart[l.FileIndex] = state.start
+		l.currentOffset[l.FileIndex] = state.offset
+		return state.page
 	}
 
+	//The file is reopened on every read, so a rotated path is read from the new file and the offset is taken from its current size
 	file, err := l.openLogFile()
 	if err != nil {
 		return &[][]string{}
 	}
 	defer file.Close()
 
-	data, _, _ := tail(file, l.Capacity, -1)
-	rows := [][]string{}
-	for _, line := range data {
-		rows = append(rows, parseLine(line, l.config.Seperator))
+	//Stat the opened file rather than the path, compressed files are read from their decompressed copy
+	sourceInfo, err := file.Stat()
+	if err != nil {
+		return &[][]string{}
 	}
 
-	l.currentOffset[l.FileIndex] = int(fileInfo.Size())
-	l.currentLoadedPage = &rows
-	l.previousReadFileInfo = fileInfo
+	start := pageStartBefore(file, l.parser(), l.viewIndex(l.FileIndex), l.Capacity, l.collapsed, int(sourceInfo.Size()))
+	rows, _ := readPage(file, l.parser(), l.Capacity, l.collapsed, start)
+
+	l.pageStart[l.FileIndex] = start
+	l.currentOffset[l.FileIndex] = int(sourceInfo.Size())
+	if _, ok := l.decompressed.stat(path); ok {
+		fileInfo = sourceInfo
+	}
+	state.fileInfo = fileInfo
+	state.fingerprint = fingerprint
+	state.page = &rows
+	state.start = start
+	state.offset = int(sourceInfo.Size())
+	l.refreshIndex(l.FileIndex)
 	return &rows
 }
 
-//Reads the first N lines where N=The capacity configuration value
+//Returns the truncation or rotation of the active file detected by the last tail and clears it
+//Returns an empty string if nothing was detected
+func (l *LogReader) RotationNotice() string {
+	if l.FileIndex >= len(l.tailStates) {
+		return ""
+	}
+	notice := l.tailStates[l.FileIndex].notice
+	l.tailStates[l.FileIndex].notice = ""
+	return notice
+}
+
+//Reads the first page of whole records, see Tail
 //Returns a two dimensional slice containing the parsed rows
 func (l *LogReader) Head() *[][]string {
 	file, err := l.openLogFile()
@@ -100,16 +390,10 @@ func (l *LogReader) Head() *[][]string {
 	}
 	defer file.Close()
 
-	data, offset, _ := head(file, l.Capacity, 0)
-	rows := [][]string{}
-	for _, line := range data {
-		rows = append(rows, parseLine(line, l.config.Seperator))
-	}
-	l.currentOffset[l.FileIndex] = offset
-	return &rows
+	return l.showPage(file, 0)
 }
 
-//Reads the last N lines where N=The capacity configuration value starting from the current offset excluding the last page
+//Reads the page of whole records ending at the first line of the current page
 //Returns a two dimensional slice containing the parsed rows
 func (l *LogReader) PageUp() *[][]string {
 	file, err := l.openLogFile()
@@ -118,12 +402,15 @@ func (l *LogReader) PageUp() *[][]string {
 	}
 	defer file.Close()
 
-	data, offset := readLogFileFromOffsetUp(file, l.config.Seperator, l.Capacity, l.currentOffset[l.FileIndex])
-	l.currentOffset[l.FileIndex] = offset
-	return data
+	start := l.pageStart[l.FileIndex]
+	//A page past the end of the file is left over from before the file was truncated, the page before the last page is read
+	if fileInfo, err := file.Stat(); err == nil && start > int(fileInfo.Size()) {
+		start = pageStartBefore(file, l.parser(), l.viewIndex(l.FileIndex), l.Capacity, l.collapsed, int(fileInfo.Size()))
+	}
+	return l.showPage(file, pageStartBefore(file, l.parser(), l.viewIndex(l.FileIndex), l.Capacity, l.collapsed, start))
 }
 
-//Reads the last N lines where N=The capacity configuration value starting from the first line after the current page
+//Reads the page of whole records starting at the first line after the current page
 //Returns a two dimensional slice containing the parsed rows
 func (l *LogReader) PageDown() *[][]string {
 	file, err := l.openLogFile()
@@ -132,12 +419,10 @@ func (l *LogReader) PageDown() *[][]string {
 	}
 	defer file.Close()
 
-	data, offset := readLogFileFromOffsetDown(file, l.config.Seperator, l.Capacity, l.currentOffset[l.FileIndex])
-	l.currentOffset[l.FileIndex] = offset
-	return data
+	return l.showPage(file, l.currentOffset[l.FileIndex])
 }
 
-//Reads the last N lines where N=The capacity configuration value starting from the current offset excluding the last line
+//Moves the current page up by one record, an entry and its continuation lines
 //Returns a two dimensional slice containing the parsed rows
 func (l *LogReader) Up() *[][]string {
 	file, err := l.openLogFile()
@@ -146,51 +431,291 @@ func (l *LogReader) Up() *[][]string {
 	}
 	defer file.Close()
 
-	lastLine, _, _ := lastLine(file, l.Capacity, l.currentOffset[l.FileIndex])
-	lastPosition := tailStartPosition(file, l.Capacity, l.currentOffset[l.FileIndex]-len(lastLine))
-	if lastPosition < 0 {
-		lastPosition = 0
+	return l.showPage(file, pageStartBefore(file, l.parser(), l.viewIndex(l.FileIndex), 1, true, l.pageStart[l.FileIndex]))
+}
+
+//Moves the current page down by one record, an entry and its continuation lines
+//Returns a two dimensional slice containing the parsed rows
+func (l *LogReader) Down() *[][]string {
+	file, err := l.openLogFile()
+	if err != nil {
+		return &[][]string{}
 	}
+	defer file.Close()
 
-	data, offset := readLogFileFromOffsetDown(file, l.config.Seperator, l.Capacity, lastPosition )
-	l.currentOffset[l.FileIndex] = offset
-	return data
+	_, next := readPage(file, l.parser(), 1, true, l.pageStart[l.FileIndex])
+	return l.showPage(file, next)
 }
 
-//Reads the last N lines where N=The capacity configuration value starting from the current line + 1
+//Navigates to the page starting at the record holding the line, counting lines from 1
+//Uses the line index so only the lines after the closest checkpoint are read, lines past the end of the file show the last page
 //Returns a two dimensional slice containing the parsed rows
-func (l *LogReader) Down() *[][]string {
+func (l *LogReader) SeekLine(line int) *[][]string {
 	file, err := l.openLogFile()
 	if err != nil {
 		return &[][]string{}
 	}
 	defer file.Close()
 
-	nextLine, _, _ := nextLine(file, l.currentOffset[l.FileIndex])
-	data, offset := readLogFileFromOffsetDown(file, l.config.Seperator, l.Capacity, tailStartPosition(file, l.Capacity, l.currentOffset[l.FileIndex]+len(nextLine)+1))
-	l.currentOffset[l.FileIndex] = offset
-	return data
+	if line < 1 {
+		line = 1
+	}
+	l.refreshIndex(l.FileIndex)
+	lineOffset := l.viewIndex(l.FileIndex).lineOffset(file, line-1)
+
+	return l.showPage(file, l.pageStartAt(file, int(lineOffset)))
 }
 
-//Search the log file for a search term
+//Navigates to the page starting at the record holding the first line after the percentage of the file size
+//Returns a two dimensional slice containing the parsed rows
+func (l *LogReader) SeekPercent(percent int) *[][]string {
+	file, err := l.openLogFile()
+	if err != nil {
+		return &[][]string{}
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return &[][]string{}
+	}
+	if percent < 0 {
+		percent = 0
+	} else if percent > 100 {
+		percent = 100
+	}
+	offset := int(fileInfo.Size() * int64(percent) / 100)
+	//Skip the rest of the line the offset falls in, unless it's already at the start of a line
+	if offset > 0 {
+		_, offset, _ = head(file, 1, offset-1)
+	}
+
+	return l.showPage(file, l.pageStartAt(file, offset))
+}
+
+//Returns the start of the page showing the line at the offset, the start of the record holding it
+//A record starting too far before the line to show it on the page is shown from the line, see readPage
+func (l LogReader) pageStartAt(file logSource, offset int) int {
+	if l.collapsed {
+		return recordStart(file, l.parser(), l.viewIndex(l.FileIndex), offset, 0)
+	}
+	return recordStart(file, l.parser(), l.viewIndex(l.FileIndex), offset, l.Capacity)
+}
+
+//Moves the current page by the number of lines, negative numbers move up
+//A move within the first record of the page moves to the next or previous record
+//Returns a two dimensional slice containing the parsed rows
+func (l *LogReader) SeekRelative(lines int) *[][]string {
+	previous := l.pageStart[l.FileIndex]
+	page := l.SeekLine(l.CurrentLine() + lines)
+	if lines > 0 && l.pageStart[l.FileIndex] <= previous {
+		return l.Down()
+	}
+	if lines < 0 && l.pageStart[l.FileIndex] >= previous {
+		return l.Up()
+	}
+	return page
+}
+
+//Returns the number of the first line of the current page, counting lines from 1
+func (l *LogReader) CurrentLine() int {
+	file, err := l.openLogFile()
+	if err != nil {
+		return 1
+	}
+	defer file.Close()
+
+	l.refreshIndex(l.FileIndex)
+	return l.viewIndex(l.FileIndex).lineNumber(file, int64(l.pageStart[l.FileIndex])) + 1
+}
+
+//Parses a time typed by the user in the timestamp layout of the active file or a common layout
+//A time of day without a date (14:32:05) is taken to be on the date of the first entry of the current page
+//Returns an error if the file has no timestamp column or the text isn't a time
+func (l *LogReader) ParseTime(input string) (time.Time, error) {
+	timestamps := l.timestampsOf(l.FileIndex)
+	if timestamps == nil {
+		return time.Time{}, errors.New("no timestamp column configured")
+	}
+
+	reference := time.Now()
+	if file, err := l.openLogFile(); err == nil {
+		if _, timestamp, found := nextTimestamp(file, l.parser(), timestamps, l.pageStart[l.FileIndex]); found {
+			reference = timestamp
+		}
+		file.Close()
+	}
+
+	return timestamps.parseInput(input, reference)
+}
+
+//Navigates to the page starting at the first entry at or after the time, the file is binary searched so the entries must be in time order
+//Shows the last page if all entries are before the time, the current page is returned if the file has no timestamp column
+//Returns a two dimensional slice containing the parsed rows
+func (l *LogReader) SeekTime(target time.Time) *[][]string {
+	timestamps := l.timestampsOf(l.FileIndex)
+	if timestamps == nil {
+		return l.Refresh()
+	}
+	file, err := l.openLogFile()
+	if err != nil {
+		return &[][]string{}
+	}
+	defer file.Close()
+
+	//The offset is the end of the file if no entry was found, which reads the last page
+	offset, _ := searchTime(file, l.parser(), timestamps, target)
+	return l.showPage(file, offset)
+}
+
+//Search the log file for a search term, matched as a case insensitive substring
 //Returns a two dimensional slice containing the parsed rows for the location containing the search term and the location within the result
 func (l *LogReader) Search(searchTerm string, currentLocation int) (*[][]string, int) {
+	matcher, _ := NewMatcher(searchTerm, SearchOptions{})
+	return l.SearchWith(matcher, currentLocation)
+}
+
+//Searches the log file for the next line matching after the row at the location in the current page, -1 searches from the first line of the page
+//Starts over from the beginning of the file if there is no match until the end
+//Returns a two dimensional slice containing the parsed rows for the page showing the match and the location of the match within the page, -1 if nothing matches
+//The page starts at the record holding the match, while collapsed the location is the row of the record
+func (l *LogReader) SearchWith(matcher Matcher, currentLocation int) (*[][]string, int) {
+	file, err := l.openLogFile()
+	if err != nil {
+		return &[][]string{}, -1
+	}
+	defer file.Close()
+
+	//The row after the location starts after the whole record while collapsed
+	searchOffset := l.locationOffset(file, currentLocation+1)
+	matcher = l.bind(matcher, l.FileIndex)
+	location := searchFile(file, l.parser(), matcher, searchOffset)
+	if location == -1 {
+		location = searchFile(file, l.parser(), matcher, 0)
+	}
+
+	return l.searchResultPage(file, location)
+}
+
+//Searches the log file backwards for the previous line matching before the row at the location in the current page, -1 searches before the page
+//Starts over from the end of the file if there is no match until the beginning
+//Returns a two dimensional slice containing the parsed rows for the page showing the match and the location of the match within the page, -1 if nothing matches
+func (l *LogReader) SearchBackward(matcher Matcher, currentLocation int) (*[][]string, int) {
 	file, err := l.openLogFile()
 	if err != nil {
-		return &[][]string{}, 0
+		return &[][]string{}, -1
 	}
 	defer file.Close()
 
-	searchOffset := l.currentOffset[l.FileIndex] + currentLocation + 1
-	location := searchFileForTerm(file, searchTerm, searchOffset)
+	matcher = l.bind(matcher, l.FileIndex)
+	location := searchFileBackward(file, l.parser(), l.viewIndex(l.FileIndex), matcher, l.locationOffset(file, currentLocation))
 	if location == -1 {
-		file.Seek(0, 0)
-		location = searchFileForTerm(file, searchTerm, 0)
+		if fileInfo, err := file.Stat(); err == nil {
+			location = searchFileBackward(file, l.parser(), l.viewIndex(l.FileIndex), matcher, int(fileInfo.Size()))
+		}
 	}
-	startFromLocation := location + l.Capacity
-	data, offset := readLogFileFromOffsetUp(file, l.config.Seperator, l.Capacity, startFromLocation)
-	l.currentOffset[l.FileIndex] = offset
-	resultLocationInCurrentPage := offset - location
+
+	return l.searchResultPage(file, location)
+}
+
+//Counts the lines matching in the active file in the background, the offsets of the matches are kept so only the lines added since are read on the next call
+//Returns the number of the match at the location in the current page, counting from 1, and the total number of matches
+//Returns false until the whole file was counted once, Updates receives a value once the count changed
+func (l *LogReader) MatchPosition(matcher Matcher, currentLocation int) (int, int, bool) {
+	file, err := l.openLogFile()
+	if err != nil {
+		return 0, 0, false
+	}
+	defer file.Close()
+
+	matches := l.countMatches(matcher)
+	before, total, ready := matches.position(l.locationOffset(file, currentLocation))
+	return before + 1, total, ready
+}
+
+//Returns the offsets of the lines of the active file matching, starting to read the lines added since the last count in the background
+func (l LogReader) countMatches(matcher Matcher) *matchOffsets {
+	matches := l.matches[l.FileIndex].get(matcher)
+	path, open := l.viewSource(l.FileIndex)
+	matches.refresh(path, open, l.parser(), l.bind(matcher, l.FileIndex), l.notify)
+	return matches
+}
+
+//Binds a query or column matcher to the headers of the file at the index so it matches the columns of its lines, other matchers are returned as they are
+func (l LogReader) bind(matcher Matcher, index int) Matcher {
+	switch columns := matcher.(type) {
+	case entriesMatcher:
+		return entriesMatcher{l.bind(columns.Matcher, index)}
+	case *Query:
+		return columns.bind(l.headersOf(index))
+	case ColumnMatcher:
+		headers := l.headersOf(index)
+		names := make([]string, len(headers))
+		for headerIndex, header := range headers {
+			names[headerIndex] = header.Header
+		}
+		return boundColumns{columns, names}
+	}
+	return matcher
+}
+
+//Checks that a query only uses the headers of the active file
+//Returns an error naming the unknown header, nil for other matchers
+func (l LogReader) CheckMatcher(matcher Matcher) error {
+	if query, ok := matcher.(*Query); ok {
+		return query.check(l.headers())
+	}
+	return nil
+}
+
+//Counts the lines matching in the active file above and below the current page in the background
+//The offsets of the matching lines are kept for the next call, only the lines added since are read then
+//Returns the number of matches before the first line of the page and after its last line
+//Returns false until the whole file was counted once, Updates receives a value once the count changed
+func (l *LogReader) MatchesAround(matcher Matcher) (int, int, bool) {
+	file, err := l.openLogFile()
+	if err != nil {
+		return 0, 0, false
+	}
+	defer file.Close()
+
+	matches := l.countMatches(matcher)
+	end := l.currentOffset[l.FileIndex]
+	if fileInfo, err := file.Stat(); err == nil && end > int(fileInfo.Size()) {
+		end = int(fileInfo.Size())
+	}
+	return matches.around(l.pageStart[l.FileIndex], end)
+}
+
+//Returns the offset of the start of the row at the location in the current page, -1 is the start of the page
+//The row of a collapsed record starts at its entry line
+func (l *LogReader) locationOffset(file logSource, location int) int {
+	offset := l.pageStart[l.FileIndex]
+	if location <= 0 {
+		return offset
+	}
+	if l.collapsed {
+		_, offset = readPage(file, l.parser(), location, true, offset)
+	} else {
+		_, offset, _ = head(file, location, offset)
+	}
+	return offset
+}
+
+//Reads the page starting at the record holding the matching line at the offset, or the current page if the offset is -1
+//Returns a two dimensional slice containing the parsed rows and the location of the match within the page, -1 if nothing matched
+func (l *LogReader) searchResultPage(file logSource, location int) (*[][]string, int) {
+	if location == -1 {
+		return l.showPage(file, l.pageStart[l.FileIndex]), -1
+	}
+
+	data := l.showPage(file, l.pageStartAt(file, location))
+	//The page starts before the match when the match is on the last page
+	pageStart := l.pageStart[l.FileIndex]
+	if l.collapsed {
+		return data, countRecords(file, l.parser(), pageStart, location)
+	}
+	resultLocationInCurrentPage, _ := countNewLines(file, int64(pageStart), int64(location))
 
 	return data, resultLocationInCurrentPage
 }
@@ -218,8 +743,9 @@ func parseLine(line string, delim string) []string {
 
 //Gets a slice of strings representing the headers of the log
 func (l LogReader) GetHeaders() []string {
-	headers := make([]string, len(l.config.Headers))
-	for index, header := range l.config.Headers {
+	columns := l.headers()
+	headers := make([]string, len(columns))
+	for index, header := range columns {
 		headers[index] = header.Header
 	}
 	return headers
@@ -227,13 +753,43 @@ func (l LogReader) GetHeaders() []string {
 
 //Gets a slice of strings representing the headers of the log
 func (l LogReader) GetColumnSizes() []int {
-	sizes := make([]int, len(l.config.Headers))
-	for index, header := range l.config.Headers {
+	columns := l.headers()
+	sizes := make([]int, len(columns))
+	for index, header := range columns {
 		sizes[index] = header.Size
 	}
 	return sizes
 }
 
+//Returns the headers of the active file followed by any columns added by its parser
+func (l LogReader) headers() []Header {
+	return l.headersOf(l.FileIndex)
+}
+
+//Returns the headers of the file at the index followed by any columns added by its parser
+//The merged view starts with the name of the file each row comes from, followed by the top level headers
+func (l LogReader) headersOf(index int) []Header {
+	if l.isMerged(index) {
+		source := Header{Header: sourceHeader, Size: len(sourceHeader)}
+		for _, file := range l.config.Files {
+			if len(file.Name) > source.Size {
+				source.Size = len(file.Name)
+			}
+		}
+		headers := append([]Header{source}, l.parsers[index].(mergedParser).headers...)
+		if l.collapsed {
+			headers = append(headers, Header{Header: LinesHeader, Size: 6})
+		}
+		return headers
+	}
+	headers := l.config.fileConfig(index).Headers
+	extra, ok := l.rowParser(index).(extraColumns)
+	if !ok {
+		return headers
+	}
+	return append(append([]Header{}, headers...), extra.extraHeaders()...)
+}
+
 //Sets the number of rows to display capacity
 func (l *LogReader) SetCapacity(capacity int) {
 	l.Capacity = capacity
@@ -244,6 +800,7 @@ func (l *LogReader) CurrentOffset() int {
 	return l.currentOffset[l.FileIndex]
 }
 
+//Returns the whole log entry shown at the row of the current page, counting from 1, a continuation line shows the entry it belongs to
 func (l *LogReader) Message(lineNum int) string {
 	file, err := l.openLogFile()
 	if err != nil {
@@ -252,15 +809,7 @@ func (l *LogReader) Message(lineNum int) string {
 
 	defer file.Close()
 
-	data, _, _ := tail(file, l.Capacity, l.currentOffset[l.FileIndex])
-
-	message := data[lineNum - 1]
-	fmt.Println(message)
-	if !strings.Contains(message, l.config.Seperator) {
-		message = stackTrace(file, l.currentOffset[l.FileIndex], l.config.Seperator)
-	}
-
-	return message
+	return logEntry(file, l.locationOffset(file, lineNum-1), l.parser(), l.viewIndex(l.FileIndex))
 }
 
 func (l *LogReader) Progress() int {
@@ -275,54 +824,250 @@ func (l *LogReader) Progress() int {
 	return percentage
 }
 
-//Reads N (N=capacity) lines starting from the offset
-//Returns a two dimensional array containing the parsed columns and the new offset
-func readLogFileFromOffsetUp(file *os.File, delim string, capacity int, offset int) (*[][]string, int) {
-	data, newOffset, _ := tail(file, capacity, tailStartPosition(file, capacity, offset))
-	rows := [][]string{}
-	if len(data) == 0 {
-		return &rows, 0
+//Opens the active log file, compressed files (gzip, bzip2 and zstd) are opened through their decompressed copy
+//Files with a rotation glob are opened together with their rotated siblings as one stream, the merged view is opened through its merged copy
+//While filters are set the filtered copy of the file is opened instead
+func (l LogReader) openLogFile() (logSource, error) {
+	return l.openView(l.FileIndex)
+}
+
+//Opens the file at the index as it is shown, filtered if it has filters
+//The filtered copy is opened as far as it's written, see Updates
+func (l LogReader) openView(index int) (logSource, error) {
+	if len(l.filters[index]) == 0 {
+		return l.openFile(index)
 	}
-	for _, line := range data {
-		rows = append(rows, parseLine(line, delim))
+	if err := l.updateView(index); err != nil {
+		return nil, err
 	}
+	return os.Open(l.filtered[index].path)
+}
 
-	return &rows, int(newOffset)
+//Starts copying the entries added to the file at the index that pass its filters to its filtered copy in the background
+func (l LogReader) updateView(index int) error {
+	source, err := l.openFile(index)
+	if err != nil {
+		return err
+	}
+	//The merged copy only exists once the merged view was opened
+	fileInfo, err := os.Stat(l.filePath(index))
+	if err != nil {
+		source.Close()
+		return err
+	}
+	filters := make([]Filter, len(l.filters[index]))
+	for filterIndex, filter := range l.filters[index] {
+		filters[filterIndex] = Filter{l.bind(filter.Matcher, index), filter.Exclude}
+	}
+	return l.filtered[index].refresh(source, fileInfo, l.parserOf(index), filters, l.notify)
 }
 
-//Reads N (N=capacity) lines starting from the offset
-//Returns a two dimensional array containing the parsed columns and the new offset
-func readLogFileFromOffsetDown(file *os.File, delim string, capacity int, offset int) (*[][]string, int) {
-	fileInfo, _ := file.Stat()
-	data, newOffset, _ := head(file, capacity, offset)
-	if newOffset >= int(fileInfo.Size()) {
-		data, newOffset, _ = tail(file, capacity, -1)
+//Returns a channel receiving a value when a view copied in the background has grown, the page shown should be read again
+//Values are dropped while the last one wasn't received
+func (l LogReader) Updates() <-chan struct{} {
+	return l.updates
+}
+
+//Reports that a view copied in the background has grown, see Updates
+func (l LogReader) notify() {
+	select {
+	case l.updates <- struct{}{}:
+	default:
 	}
+}
+
+//Returns the path the file at the index is shown from, the filtered copy if it has filters
+func (l LogReader) viewPath(index int) string {
+	if len(l.filters[index]) > 0 {
+		return l.filtered[index].path
+	}
+	return l.filePath(index)
+}
+
+//Returns the line index of the file at the index as it is shown
+func (l LogReader) viewIndex(index int) *lineIndex {
+	if len(l.filters[index]) > 0 {
+		return l.filtered[index].index
+	}
+	return l.indexes[index]
+}
+
+//Shows only the entries of the active file passing the filters, each entry is matched with its continuation lines, the other files keep their own filters
+//Filters stack, an entry must match all include filters and none of the exclude filters, no filters show every entry
+//Navigation, search and progress work over the filtered entries, the position in the active file is reset
+//The filtered entries are copied in the background, the view shows the part copied so far, see Updates
+func (l *LogReader) SetFilters(filters []Filter) {
+	l.filters[l.FileIndex] = filters
+	l.resetView(l.FileIndex)
+	l.resetPosition(l.FileIndex)
+}
 
-	rows := [][]string{}
-	if len(data) == 0 {
-		return &rows, 0
+//Shows every entry and its continuation lines as one row, the row of an entry counts its continuation lines
+//Only the rows change, pages are made of whole records either way, every file keeps its position from the start of the record it's in
+func (l *LogReader) SetCollapsed(collapsed bool) {
+	l.collapsed = collapsed
+	for index := range l.tailStates {
+		//The last page is read again with the new rows
+		l.tailStates[index].page = nil
+		if l.pageStart[index] == 0 {
+			continue
+		}
+		if file, err := l.openView(index); err == nil {
+			l.pageStart[index] = recordStart(file, l.parserOf(index), l.viewIndex(index), l.pageStart[index], 0)
+			file.Close()
+		}
+	}
+}
+
+//Returns true if the entries are collapsed into records, see SetCollapsed
+func (l LogReader) Collapsed() bool {
+	return l.collapsed
+}
+
+//Removes the filtered copy of the file at the index, the next read filters the file from the start
+func (l *LogReader) resetView(index int) {
+	l.filtered[index].remove()
+	l.filtered[index] = newFilteredView()
+	l.tailStates[index] = tailState{}
+}
+
+//Returns true if the file at the index is shown from a copy the reader writes, the filtered and merged copies
+func (l LogReader) ownsView(index int) bool {
+	return len(l.filters[index]) > 0 || l.isMerged(index)
+}
+
+//Starts the matches and position of the file at the index over, its view has changed
+func (l *LogReader) resetPosition(index int) {
+	l.matches[index].reset()
+	l.tailStates[index] = tailState{}
+	l.pageStart[index] = 0
+	l.currentOffset[index] = 0
+}
+
+//Returns the filters of the active file set with SetFilters
+func (l LogReader) Filters() []Filter {
+	return l.filters[l.FileIndex]
+}
+
+//Opens the log file at the index, see openLogFile
+func (l LogReader) openFile(index int) (logSource, error) {
+	if l.isMerged(index) {
+		if l.merged.path == "" {
+			if err := l.updateMerged(); err != nil {
+				return nil, err
+			}
+		}
+		return os.Open(l.merged.path)
 	}
-	for _, line := range data {
-		rows = append(rows, parseLine(line, delim))
+	logFile := l.config.Files[index]
+	if logFile.RotationGlob != "" {
+		family, err := openRotatedFamily(logFile.LogFile, logFile.RotationGlob, l.decompressed)
+		if err != nil {
+			return nil, err
+		}
+		return family, nil
 	}
 
-	return &rows, int(newOffset)
+	file, err := l.decompressed.open(l.filePath(index))
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+//Indexes the lines added to the file at the index in the background
+func (l LogReader) refreshIndex(index int) {
+	path, open := l.viewSource(index)
+	l.viewIndex(index).refresh(path, open)
 }
 
-func searchFileForTerm(f *os.File, searchTerm string, currentLocation int) int{
-	scanner := bufio.NewScanner(f)
-	line := 1
-	for scanner.Scan() {
-		if line > currentLocation && strings.Contains(strings.ToLower(scanner.Text()), strings.ToLower(searchTerm)) {
-			return line
+//Returns the path the file at the index is shown from and a func opening it, for the reads running in the background
+//The copies of the file, such as the filtered copy, and the merged copy are opened as far as they are written, reading them doesn't update them
+func (l LogReader) viewSource(index int) (string, func() (logSource, error)) {
+	path := l.viewPath(index)
+	if path != l.filePath(index) || l.isMerged(index) {
+		return path, func() (logSource, error) {
+			return os.Open(path)
 		}
-		line++
 	}
+	return path, func() (logSource, error) {
+		return l.openFile(index)
+	}
+}
 
-	return -1
+//Returns the paths of the log files, in the same order as the file indexes
+//Files read from the standard input or a named pipe are reported with the path of the temp file the stream is copied to
+func (l LogReader) FilePaths() []string {
+	paths := make([]string, len(l.config.Files))
+	for index := range l.config.Files {
+		paths[index] = l.filePath(index)
+	}
+	return paths
 }
 
-func (l LogReader) openLogFile() (*os.File, error) {
-	return os.Open(l.config.Files[l.FileIndex].LogFile)
+//Returns the path the file at the index is read from, streams and the merged view are read from the temp file they are copied to
+func (l LogReader) filePath(index int) string {
+	if l.isMerged(index) {
+		return l.merged.path
+	}
+	if spool := l.spools[index]; spool != nil {
+		return spool.path
+	}
+	return l.config.Files[index].LogFile
+}
+
+//Returns the number of lines appended to the file at the index since it was last marked as seen
+func (l *LogReader) NewLines(index int) int {
+	file, err := l.openFile(index)
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return 0
+	}
+	unseen := &l.unseen[index]
+	//Nothing was marked as seen yet, start counting from the current end of the file
+	if unseen.offset == -1 {
+		unseen.offset = fileInfo.Size()
+	}
+	//The file was truncated or rotated, everything in it is new
+	if fileInfo.Size() < unseen.offset {
+		unseen.offset = 0
+		unseen.lines = 0
+	}
+	lines, _ := countNewLines(file, unseen.offset, fileInfo.Size())
+	unseen.offset = fileInfo.Size()
+	unseen.lines += lines
+
+	return unseen.lines
+}
+
+//Marks everything currently in the file at the index as seen, resetting its count of new lines
+func (l *LogReader) MarkSeen(index int) {
+	file, err := l.openFile(index)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	if fileInfo, err := file.Stat(); err == nil {
+		l.unseen[index] = unseenLines{offset: fileInfo.Size()}
+	}
+}
+
+//Removes the temporary files created while reading the logs
+func (l *LogReader) Close() {
+	l.decompressed.clear()
+	l.merged.remove()
+	for _, view := range l.filtered {
+		view.remove()
+	}
+	for _, spool := range l.spools {
+		if spool != nil {
+			spool.remove()
+		}
+	}
 }
\ No newline at end of file
@@ -7,12 +7,12 @@ import (
 
 func logreaderConfig(file string, sizes []int) LogReaderConfig{
 	return LogReaderConfig{
-		Files: []LogFile{{file, "Name"}},
+		Files: []LogFile{{LogFile: file, Name: "Name"}},
 		Seperator: "~",
 		Headers: []Header{
-			{"Date", sizes[0]},
-			{"Thread", sizes[1]},
-			{"Package", sizes[2]},
+			{Header: "Date", Size: sizes[0]},
+			{Header: "Thread", Size: sizes[1]},
+			{Header: "Package", Size: sizes[2]},
 		},
 	}
 }
@@ -212,8 +212,9 @@ func TestLogReader_Up(t *testing.T) {
 
 func TestLogReader_Up_WithStackTrace(t *testing.T) {
 	input := "../test_logs/TestLogReader_Navigate_withstacktrace.log"
+	//Up moves by whole entries, the stack trace of Thread-12 is passed in one step
 	expected := [][]string{
-		{"14/11/2010", "Thread-8", "com.test"},
+		{"14/11/2010", "Thread-7", "com.test"},
 	}
 
 	logReader := NewLogReader(logreaderConfig(input, []int{10, 10, 10}))
@@ -372,4 +373,56 @@ func TestLogReader_Progress_Head(t *testing.T) {
 	if actual != expected {
 		t.Errorf("Expected %s lines, got %s", expected, actual)
 	}
-}
\ No newline at end of file
+}
+
+func TestLogReader_GetHeaders_perFileConfiguration(t *testing.T) {
+	config := logreaderConfig("../test_logs/TestLogReader_Tail_input.log", []int{10, 10, 10})
+	config.Files = append(config.Files, LogFile{
+		LogFile:   "../test_logs/TestLogReader_Tail_input.log",
+		Name:      "Access",
+		Seperator: "/",
+		Headers:   []Header{{Header: "Day", Size: 2}, {Header: "Rest", Size: -1}},
+	})
+
+	logReader := NewLogReader(config)
+	logReader.SetCapacity(1)
+	if expected := []string{"Date", "Thread", "Package"}; !reflect.DeepEqual(logReader.GetHeaders(), expected) {
+		t.Errorf("Expected %s, got %s", expected, logReader.GetHeaders())
+	}
+
+	logReader.FileIndex = 1
+	if expected := []string{"Day", "Rest"}; !reflect.DeepEqual(logReader.GetHeaders(), expected) {
+		t.Errorf("Expected %s, got %s", expected, logReader.GetHeaders())
+	}
+	if expected := []int{2, -1}; !reflect.DeepEqual(logReader.GetColumnSizes(), expected) {
+		t.Errorf("Expected %v, got %v", expected, logReader.GetColumnSizes())
+	}
+	expected := [][]string{{"18", "11", "2010~Thread-8~com.test"}}
+	if result := *logReader.Tail(); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+}
+
+func TestLogReader_Validate_perFileConfiguration(t *testing.T) {
+	config := logreaderConfig("app.log", []int{10, 10, 10})
+	if err := config.Validate(); err != nil {
+		t.Errorf("Expected a valid configuration, got %v", err)
+	}
+
+	config.Files = append(config.Files, LogFile{LogFile: "access.log", Format: "xml"})
+	if err := config.Validate(); err == nil {
+		t.Errorf("Expected an error for the unknown format of access.log")
+	}
+
+	config = logreaderConfig("app.log", []int{10, 10, 10})
+	config.Pattern = "("
+	config.Files[0].Seperator = "~"
+	if err := config.Validate(); err != nil {
+		t.Errorf("Expected the invalid top level pattern to be ignored when no file inherits it, got %v", err)
+	}
+
+	config.Files = append(config.Files, LogFile{LogFile: "access.log"})
+	if err := config.Validate(); err == nil {
+		t.Errorf("Expected an error for the invalid pattern inherited by access.log")
+	}
+}
@@ -4,10 +4,45 @@ import (
 	"bytes"
 	"io"
 	"os"
-	"strings"
 	"bufio"
+	"strings"
 )
 
+//A seekable log to read lines from, either a single file or a rotated family of files read as one
+type logSource interface {
+	io.ReadSeeker
+	io.Closer
+	Stat() (os.FileInfo, error)
+}
+
+//Counts the lines between the start and end offsets
+//Returns the number of new line characters read
+func countNewLines(input io.ReadSeeker, start int64, end int64) (int, error) {
+	if _, err := input.Seek(start, 0); err != nil {
+		return 0, err
+	}
+
+	lines := 0
+	buf := make([]byte, 32*1024)
+	remaining := end - start
+	for remaining > 0 {
+		if int64(len(buf)) > remaining {
+			buf = buf[:remaining]
+		}
+		n, err := input.Read(buf)
+		lines += bytes.Count(buf[:n], []byte{'\n'})
+		remaining -= int64(n)
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return lines, err
+		}
+	}
+
+	return lines, nil
+}
+
 //Reads a maximum of "capacity" number of lines starting from the offset position
 //Returns a slice containing a maximum of "capacity" entries and the current position.
 func readLinesStartingFromPosition(input io.ReadSeeker, capacity int, start int) ([]string, int, error) {
@@ -47,32 +82,33 @@ func readLinesStartingFromPosition(input io.ReadSeeker, capacity int, start int)
 
 //A convenience method for tailing a file
 //Returns a slice containing the retrieved rows and the new offset
-func tail(file *os.File, capacity int, endOffset int) ([]string, int, error) {
+func tail(file logSource, capacity int, endOffset int) ([]string, int, error) {
 	tailStartPosition := tailStartPosition(file, capacity, endOffset)
 	return readLinesStartingFromPosition(file, capacity, tailStartPosition)
 }
 
 //A convenience method to head a file
 //Returns a slice containing the retrieved rows and the new offset
-func head(file *os.File, capacity int, offset int) ([]string, int, error) {
+func head(file logSource, capacity int, offset int) ([]string, int, error) {
 	return readLinesStartingFromPosition(file, capacity, offset)
 }
 
-func nextLine(file *os.File, offset int) (string, int, error) {
+func nextLine(file logSource, offset int) (string, int, error) {
 	data, offset, err := head(file, 1, offset)
 	return data[0], offset, err
 }
 
-func lastLine(file *os.File, capacity int, offset int) (string, int, error) {
+func lastLine(file logSource, capacity int, offset int) (string, int, error) {
 	data, offset, err := tail(file, capacity, offset)
 	return data[capacity-1], offset, err
 }
 
-func tailStartPosition(file *os.File, capacity int, endOffset int) int {
+func tailStartPosition(file logSource, capacity int, endOffset int) int {
 	bufferSize := 128
 	fileInfo, _ := file.Stat()
 	size := endOffset
-	if endOffset == -1 {
+	//An offset past the end of the file is left over from before the file was truncated
+	if endOffset == -1 || endOffset > int(fileInfo.Size()) {
 		size = int(fileInfo.Size())
 	}
 	buf := make([]byte, bufferSize)
@@ -179,36 +215,99 @@ func readLine(r io.Reader, lineNum int) (line string, lastLine int, err error) {
 	return line, lastLine, scanError
 }
 
-//Reads all lines related to a stack trace starting from the specified index "lineNum"
-//Returns a string representing the stack trace
-func stackTrace(r *os.File, offset int, delim string) (stackTrace string) {
-	tailStart := tailStartPosition(r, 20, offset)
-	data, newOffset, _ := head(r, 20, tailStart)
-	linesRead := 0
+//Size of the blocks read when reading a file backwards
+const backwardBlockSize = 64 * 1024
+
+//Largest block read back from a checkpoint of the line index, see backwardLines
+const indexedBlockSize = 4 * backwardBlockSize
+
+//Reads the lines of a log backwards from an offset, a block at a time
+//Where the line index of the log reaches, each block starts at the checkpoint before it so it's split into lines from a known line start
+type backwardLines struct {
+	file  logSource
+	index *lineIndex
+	//The bytes from position to the offset that weren't returned as lines yet
+	pending  []byte
+	position int
+	//Whether position is the start of a line, the start of the file or a checkpoint
+	atLineStart bool
+}
+
+//Starts reading the lines before the offset, the offset must be the start of a line
+//The index may be nil, the blocks are then read without checkpoints
+func newBackwardLines(file logSource, offset int, index *lineIndex) *backwardLines {
+	return &backwardLines{file: file, index: index, position: offset, atLineStart: offset == 0}
+}
 
+//Returns the line before the lines returned so far and the offset of its start
+//Returns false once the start of the file is reached or if the file cannot be read
+func (r *backwardLines) previous() (string, int, bool) {
 	for {
-		for _, line := range data {
-			if !strings.Contains(line, delim) {
-				stackTrace = stackTrace + "\n" + line
-			} else {
-				return stackTrace
-			}
+		lines := bytes.TrimSuffix(r.pending, []byte{'\n'})
+		lineStart := bytes.LastIndexByte(lines, '\n') + 1
+		//The first line in the buffer might start in the block before it
+		if lineStart > 0 || (r.atLineStart && len(r.pending) > 0) {
+			line := string(bytes.TrimRight(lines[lineStart:], "\r"))
+			r.pending = r.pending[:lineStart]
+			return line, r.position + lineStart, true
+		}
+		if r.position == 0 {
+			return "", 0, false
+		}
 
-			//A maximum of a 100 lines to be displayed
-			//Todo: Display the stack trace in a scrollable view.
-			if linesRead > 100 {
-				return stackTrace
+		start := r.position - backwardBlockSize
+		if start < 0 {
+			start = 0
+		}
+		r.atLineStart = start == 0
+		//Checkpoints further back than a few blocks are skipped, the lines between them are long
+		if r.index != nil {
+			if checkpoint, ok := r.index.checkpointBefore(int64(r.position)); ok && int(checkpoint) >= r.position-indexedBlockSize {
+				start = int(checkpoint)
+				r.atLineStart = true
 			}
+		}
+		block := make([]byte, r.position-start)
+		if _, err := r.file.Seek(int64(start), io.SeekStart); err != nil {
+			return "", 0, false
+		}
+		if _, err := io.ReadFull(r.file, block); err != nil {
+			return "", 0, false
+		}
+		r.pending = append(block, r.pending...)
+		r.position = start
+	}
+}
 
-			linesRead++
+//Reads the whole log entry the line starting at the offset belongs to, the entry line followed by all its continuation lines such as a stack trace
+//Continuation lines before the first entry of the file are read as an entry of their own
+//Returns the entry with each line as it was written to the log file, the entry line formatted by the parser if it formats details
+func logEntry(r logSource, offset int, parser lineParser, index *lineIndex) string {
+	offset = recordStart(r, parser, index, offset, 0)
+	if _, err := r.Seek(int64(offset), io.SeekStart); err != nil {
+		return ""
+	}
+	reader := bufio.NewReader(r)
+	var entry strings.Builder
+	for first := true; ; first = false {
+		data, err := reader.ReadString('\n')
+		line := strings.TrimRight(data, "\r\n")
+		if len(data) == 0 || (!first && parser.isEntry(line)) {
+			break
 		}
-		data, newOffset, _ = head(r, 20, newOffset)
 
-		if len(data) == 0 {
+		if !first {
+			entry.WriteString("\n")
+			entry.WriteString(logText(parser, line))
+		} else if formatter, ok := parser.(detailsFormatter); ok && parser.isEntry(line) {
+			entry.WriteString(formatter.formatDetails(line))
+		} else {
+			entry.WriteString(logText(parser, line))
+		}
+		if err != nil {
 			break
 		}
 	}
 
-
-	return stackTrace
+	return entry.String()
 }
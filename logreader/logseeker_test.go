@@ -1,8 +1,10 @@
 package logreader
 
 import (
+	"fmt"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -30,3 +32,29 @@ func TestLogSeeker_readFileFromEnd_chainNonZeroOffsets(t *testing.T) {
 		t.Errorf("Expected entries %s and offset %d, got %s and offset %d", expectedEntries, expectedOffset, actualEntries, actualOffset)
 	}
 }
+
+func TestLogSeeker_logEntry_wholeStackTrace(t *testing.T) {
+	var trace strings.Builder
+	for line := 1; line <= 250; line++ {
+		fmt.Fprintf(&trace, "\tat com.test.Frame.call(Frame.java:%d)\n", line)
+	}
+	content := "11/11/2010~Thread-1~com.test\n12/11/2010~Thread-2~com.test\njava.lang.IllegalStateException: failed\n" + trace.String() + "13/11/2010~Thread-3~com.test\n"
+	path, cleanup := tempLog(t, content)
+	defer cleanup()
+
+	file, _ := os.Open(path)
+	defer file.Close()
+	parser := separatorParser{"~"}
+	expected := "12/11/2010~Thread-2~com.test\njava.lang.IllegalStateException: failed\n" + strings.TrimSuffix(trace.String(), "\n")
+	//From the entry line and from a continuation line deep in the trace
+	for _, offset := range []int{29, strings.Index(content, "Frame.java:200")} {
+		lineStart := strings.LastIndex(content[:offset], "\n") + 1
+		if actual := logEntry(file, lineStart, parser, nil); actual != expected {
+			t.Errorf("Expected the whole entry from offset %d, got %d lines", lineStart, strings.Count(actual, "\n")+1)
+		}
+	}
+
+	if actual := logEntry(file, 0, parser, nil); actual != "11/11/2010~Thread-1~com.test" {
+		t.Errorf("Expected an entry without continuation lines, got %q", actual)
+	}
+}
@@ -0,0 +1,166 @@
+package logreader
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+//How often the files that can't be watched with file system notifications are checked for changes
+const pollInterval = time.Second
+
+//Lines appended to a file since it was last seen, counted incrementally up to the offset
+//An offset of -1 means the file wasn't marked as seen yet
+type unseenLines struct {
+	offset int64
+	lines  int
+}
+
+//Notifies about changes to the log files
+//Uses file system notifications (inotify on Linux) and falls back to polling the files that can't be watched, for example on network file systems
+type LogWatcher struct {
+	//Receives a value whenever one or more files changed, use Changed to get which ones
+	Notify  chan struct{}
+	paths   []string
+	watcher *fsnotify.Watcher
+	polled  []int
+	mutex   sync.Mutex
+	changed map[int]bool
+	done    chan struct{}
+}
+
+//Starts watching the files, the index of each path in the slice identifies it in Changed
+func NewLogWatcher(paths []string) *LogWatcher {
+	w := &LogWatcher{
+		Notify:  make(chan struct{}, 1),
+		paths:   paths,
+		changed: make(map[int]bool),
+		done:    make(chan struct{}),
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		for index := range paths {
+			w.polled = append(w.polled, index)
+		}
+	} else {
+		w.watcher = watcher
+		//Watch the directories rather than the files so that rotated and recreated files keep being followed
+		watchedDirectories := make(map[string]bool)
+		for index, path := range paths {
+			directory := filepath.Dir(path)
+			if !watchedDirectories[directory] {
+				if err := watcher.Add(directory); err != nil {
+					w.polled = append(w.polled, index)
+					continue
+				}
+				watchedDirectories[directory] = true
+			}
+		}
+		go w.watch()
+	}
+
+	if len(w.polled) > 0 {
+		go w.poll()
+	}
+
+	return w
+}
+
+//Returns the indexes of the files that changed since the last call
+func (w *LogWatcher) Changed() []int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	indexes := make([]int, 0, len(w.changed))
+	for index := range w.changed {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	w.changed = make(map[int]bool)
+
+	return indexes
+}
+
+//Stops watching the files
+func (w *LogWatcher) Close() {
+	close(w.done)
+	if w.watcher != nil {
+		w.watcher.Close()
+	}
+}
+
+//Records the change and wakes up the listener, multiple changes before the listener runs are reported together
+func (w *LogWatcher) markChanged(index int) {
+	w.mutex.Lock()
+	w.changed[index] = true
+	w.mutex.Unlock()
+
+	select {
+	case w.Notify <- struct{}{}:
+	default:
+	}
+}
+
+//Forwards the file system notifications for the watched files
+func (w *LogWatcher) watch() {
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			for index, path := range w.paths {
+				if filepath.Clean(event.Name) == filepath.Clean(path) {
+					w.markChanged(index)
+				}
+			}
+		case _, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+		case <-w.done:
+			return
+		}
+	}
+}
+
+//Checks the files that can't be watched for changes in size, modification time or identity
+func (w *LogWatcher) poll() {
+	previous := make(map[int]os.FileInfo)
+	for _, index := range w.polled {
+		previous[index], _ = os.Stat(w.paths[index])
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			for _, index := range w.polled {
+				fileInfo, _ := os.Stat(w.paths[index])
+				if fileChanged(previous[index], fileInfo) {
+					w.markChanged(index)
+				}
+				previous[index] = fileInfo
+			}
+		case <-w.done:
+			return
+		}
+	}
+}
+
+//Returns true if the file was created, removed, replaced or modified
+func fileChanged(previous os.FileInfo, current os.FileInfo) bool {
+	if previous == nil || current == nil {
+		return (previous == nil) != (current == nil)
+	}
+
+	return !os.SameFile(previous, current) ||
+		previous.Size() != current.Size() ||
+		!previous.ModTime().Equal(current.ModTime())
+}
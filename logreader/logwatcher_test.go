@@ -0,0 +1,89 @@
+package logreader
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLogWatcher_notifiesChangedFile(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	first := filepath.Join(dir, "first.log")
+	second := filepath.Join(dir, "second.log")
+	writeTestLog(t, first, "11/11/2010~Thread-1~com.test\n")
+	writeTestLog(t, second, "11/11/2010~Thread-1~com.test\n")
+
+	watcher := NewLogWatcher([]string{first, second})
+	defer watcher.Close()
+
+	file, _ := os.OpenFile(second, os.O_APPEND|os.O_WRONLY, 0644)
+	file.WriteString("12/11/2010~Thread-2~com.test\n")
+	file.Close()
+
+	select {
+	case <-watcher.Notify:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected a notification after the file changed")
+	}
+	if changed := watcher.Changed(); !reflect.DeepEqual(changed, []int{1}) {
+		t.Errorf("Expected only the second file to change, got %v", changed)
+	}
+}
+
+func TestLogWatcher_fileChanged(t *testing.T) {
+	path, cleanup := tempLog(t, "11/11/2010~Thread-1~com.test\n")
+	defer cleanup()
+	before, _ := os.Stat(path)
+
+	if fileChanged(before, before) {
+		t.Errorf("Expected an unchanged file not to be reported")
+	}
+	if !fileChanged(nil, before) || !fileChanged(before, nil) {
+		t.Errorf("Expected created and removed files to be reported")
+	}
+
+	writeTestLog(t, path, "11/11/2010~Thread-1~com.test\n12/11/2010~Thread-2~com.test\n")
+	after, _ := os.Stat(path)
+	if !fileChanged(before, after) {
+		t.Errorf("Expected a file that grew to be reported")
+	}
+}
+
+func TestLogReader_NewLines_countsLinesSinceSeen(t *testing.T) {
+	path, cleanup := tempLog(t, "11/11/2010~Thread-1~com.test\n")
+	defer cleanup()
+
+	logReader := NewLogReader(logreaderConfig(path, []int{10, 10, 10}))
+	if lines := logReader.NewLines(0); lines != 0 {
+		t.Errorf("Expected no new lines before the file changed, got %d", lines)
+	}
+
+	writeTestLog(t, path, "11/11/2010~Thread-1~com.test\n12/11/2010~Thread-2~com.test\n13/11/2010~Thread-3~com.test\n")
+	if lines := logReader.NewLines(0); lines != 2 {
+		t.Errorf("Expected 2 new lines, got %d", lines)
+	}
+
+	logReader.MarkSeen(0)
+	if lines := logReader.NewLines(0); lines != 0 {
+		t.Errorf("Expected the count to be reset once seen, got %d", lines)
+	}
+}
+
+func TestLogReader_NewLines_countsLinesAppendedBeforeTheFirstCall(t *testing.T) {
+	path, cleanup := tempLog(t, "11/11/2010~Thread-1~com.test\n")
+	defer cleanup()
+
+	logReader := NewLogReader(logreaderConfig(path, []int{10, 10, 10}))
+	//Following marks every file as seen when it starts
+	logReader.MarkSeen(0)
+	file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	file.WriteString("12/11/2010~Thread-2~com.test\n")
+	file.Close()
+
+	if lines := logReader.NewLines(0); lines != 1 {
+		t.Errorf("Expected the line appended before the first count, got %d", lines)
+	}
+}
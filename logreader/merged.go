@@ -0,0 +1,407 @@
+package logreader
+
+import (
+	"bufio"
+	"container/heap"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//Header of the column showing which file each row of the merged view comes from
+const sourceHeader = "Source"
+
+//Separates the index of the source file from the line in the merged copy
+const sourceSeparator = "\x1e"
+
+//A log entry and its continuation lines, read from one of the merged files
+type mergedRecord struct {
+	file  int
+	time  time.Time
+	lines []string
+}
+
+//Reads the records of one file from where the last merge stopped up to the last complete line
+type mergeCursor struct {
+	file       int
+	source     logSource
+	reader     *bufio.Reader
+	remaining  int64
+	consumed   int64
+	parser     lineParser
+	timestamps *timestampParser
+	lastTime   time.Time
+	fallback   time.Time
+	pending    string
+	hasPending bool
+	next       *mergedRecord
+}
+
+//Orders the cursors by the time of their next record, records with the same time keep the order of the files
+type mergeHeap []*mergeCursor
+
+func (h mergeHeap) Len() int { return len(h) }
+func (h mergeHeap) Less(i, j int) bool {
+	if h[i].next.time.Equal(h[j].next.time) {
+		return h[i].file < h[j].file
+	}
+	return h[i].next.time.Before(h[j].next.time)
+}
+func (h mergeHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *mergeHeap) Push(x interface{}) { *h = append(*h, x.(*mergeCursor)) }
+func (h *mergeHeap) Pop() interface{} {
+	old := *h
+	cursor := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return cursor
+}
+
+//Where a record starts in the merged copy and the time it was merged by
+type mergedMark struct {
+	time   time.Time
+	offset int64
+}
+
+//The log files interleaved by the time of their entries, copied to a temp file so it can be read like a single log
+//Each line of the copy is prefixed by the index of the file it comes from
+//Records older than the last ones copied are merged with them, the copy is rewritten from the first record after them
+type mergedSpool struct {
+	mutex     sync.Mutex
+	path      string
+	size      int64
+	marks     []mergedMark
+	files     []os.FileInfo
+	offsets   []int64
+	lastTimes []time.Time
+}
+
+func newMergedSpool(files int) *mergedSpool {
+	return &mergedSpool{
+		files:     make([]os.FileInfo, files),
+		offsets:   make([]int64, files),
+		lastTimes: make([]time.Time, files),
+	}
+}
+
+//Merges the lines added to the files since the last update into the copy, creating it on the first update
+//Files that were truncated or replaced are read again from the start
+//Returns the offset the copy was rewritten from, -1 if the records were only appended
+//Returns an error if the copy cannot be created or written
+func (s *mergedSpool) update(paths []string, open func(index int) (logSource, error), parsers []lineParser, timestamps []*timestampParser) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.path == "" {
+		spool, err := ioutil.TempFile("", "golog-merged-*.log")
+		if err != nil {
+			return -1, err
+		}
+		s.path = spool.Name()
+		spool.Close()
+	}
+
+	//Entries read before the first timed entry of their file are placed after the last record copied
+	var latest time.Time
+	if len(s.marks) > 0 {
+		latest = s.marks[len(s.marks)-1].time
+	}
+	var opened []*mergeCursor
+	cursors := &mergeHeap{}
+	for index := range s.offsets {
+		cursor := s.openCursor(index, paths[index], open, parsers[index], timestamps[index])
+		if cursor == nil {
+			continue
+		}
+		defer cursor.source.Close()
+		cursor.fallback = latest
+		opened = append(opened, cursor)
+		if cursor.advance() {
+			*cursors = append(*cursors, cursor)
+		}
+	}
+	heap.Init(cursors)
+
+	rewritten := int64(-1)
+	var copied []*mergedRecord
+	if cursors.Len() > 0 {
+		earliest := (*cursors)[0].next.time
+		first := sort.Search(len(s.marks), func(i int) bool {
+			return s.marks[i].time.After(earliest)
+		})
+		if first < len(s.marks) {
+			records, err := s.readRecords(first)
+			if err != nil {
+				return -1, err
+			}
+			rewritten, copied = s.marks[first].offset, records
+			if err := os.Truncate(s.path, rewritten); err != nil {
+				return -1, err
+			}
+			s.size, s.marks = rewritten, s.marks[:first]
+		}
+	}
+
+	spool, err := os.OpenFile(s.path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return -1, err
+	}
+	defer spool.Close()
+	writer := bufio.NewWriter(spool)
+	for cursors.Len() > 0 || len(copied) > 0 {
+		//Records already copied stay before the new records with the same time
+		if len(copied) > 0 && (cursors.Len() == 0 || !(*cursors)[0].next.time.Before(copied[0].time)) {
+			s.write(writer, copied[0])
+			copied = copied[1:]
+			continue
+		}
+		cursor := (*cursors)[0]
+		s.write(writer, cursor.next)
+		if cursor.advance() {
+			heap.Fix(cursors, 0)
+		} else {
+			heap.Pop(cursors)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return -1, err
+	}
+
+	for _, cursor := range opened {
+		s.offsets[cursor.file] += cursor.consumed
+		s.lastTimes[cursor.file] = cursor.lastTime
+	}
+	return rewritten, nil
+}
+
+//Writes the lines of the record prefixed by the index of its file and marks where it starts
+func (s *mergedSpool) write(writer *bufio.Writer, record *mergedRecord) {
+	s.marks = append(s.marks, mergedMark{record.time, s.size})
+	for _, line := range record.lines {
+		written, _ := fmt.Fprintf(writer, "%d%s%s\n", record.file, sourceSeparator, line)
+		s.size += int64(written)
+	}
+}
+
+//Reads back the records of the copy from the mark at the index to its end
+func (s *mergedSpool) readRecords(first int) ([]*mergedRecord, error) {
+	spool, err := os.Open(s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer spool.Close()
+	offset := s.marks[first].offset
+	if _, err := spool.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	var records []*mergedRecord
+	reader := bufio.NewReader(spool)
+	next := first
+	for offset < s.size {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		if next < len(s.marks) && s.marks[next].offset == offset {
+			records = append(records, &mergedRecord{time: s.marks[next].time})
+			next++
+		}
+		record := records[len(records)-1]
+		file, text, _ := splitSource(strings.TrimSuffix(line, "\n"), len(s.files))
+		record.file, record.lines = file, append(record.lines, text)
+		offset += int64(len(line))
+	}
+	return records, nil
+}
+
+//Opens the file at the index positioned where the last update stopped
+//Returns nil if the file cannot be read
+func (s *mergedSpool) openCursor(index int, path string, open func(index int) (logSource, error), parser lineParser, timestamps *timestampParser) *mergeCursor {
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	file, err := open(index)
+	if err != nil {
+		return nil
+	}
+	sourceInfo, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil
+	}
+	if s.files[index] != nil && (!os.SameFile(s.files[index], fileInfo) || sourceInfo.Size() < s.offsets[index]) {
+		s.offsets[index] = 0
+	}
+	s.files[index] = fileInfo
+	if _, err := file.Seek(s.offsets[index], io.SeekStart); err != nil {
+		file.Close()
+		return nil
+	}
+
+	return &mergeCursor{
+		file:       index,
+		source:     file,
+		reader:     bufio.NewReader(file),
+		remaining:  sourceInfo.Size() - s.offsets[index],
+		parser:     parser,
+		timestamps: timestamps,
+		lastTime:   s.lastTimes[index],
+	}
+}
+
+//Reads the next complete line, lines still being written (without a trailing new line) are left for the next update
+func (c *mergeCursor) readLine() (string, bool) {
+	if c.remaining <= 0 {
+		return "", false
+	}
+	line, err := c.reader.ReadString('\n')
+	if err != nil || int64(len(line)) > c.remaining {
+		c.remaining = 0
+		return "", false
+	}
+	c.remaining -= int64(len(line))
+	c.consumed += int64(len(line))
+	return strings.TrimRight(line, "\r\n"), true
+}
+
+//Reads the next record into next
+//Returns false once all complete lines were read
+func (c *mergeCursor) advance() bool {
+	var first string
+	if c.hasPending {
+		first, c.hasPending = c.pending, false
+	} else {
+		line, ok := c.readLine()
+		if !ok {
+			c.next = nil
+			return false
+		}
+		first = line
+	}
+
+	//Entries without a time of their own are placed with the previous entry of the file
+	if c.parser.isEntry(first) && c.timestamps != nil {
+		if timestamp, ok := c.timestamps.parse(c.parser.parse(first)); ok {
+			c.lastTime = timestamp
+		}
+	}
+	recordTime := c.lastTime
+	if recordTime.IsZero() {
+		recordTime = c.fallback
+	}
+	record := &mergedRecord{c.file, recordTime, []string{first}}
+	for {
+		line, ok := c.readLine()
+		if !ok {
+			break
+		}
+		if c.parser.isEntry(line) {
+			c.pending, c.hasPending = line, true
+			break
+		}
+		record.lines = append(record.lines, line)
+	}
+	c.next = record
+	return true
+}
+
+//Removes the merged copy
+func (s *mergedSpool) remove() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.path != "" {
+		os.Remove(s.path)
+	}
+}
+
+//Parses the lines of the merged copy with the parser of the file each line comes from
+//Entries get the name of their file as the first column followed by the headers of all files, each file fills the columns of its own headers
+//Continuation lines are returned as a single column
+type mergedParser struct {
+	names   []string
+	parsers []lineParser
+	headers []Header
+	columns [][]int
+}
+
+//Returns the parser of the merged copy, headers with the same name in several files share a column
+func newMergedParser(names []string, parsers []lineParser, headers [][]Header) mergedParser {
+	parser := mergedParser{names: names, parsers: parsers, columns: make([][]int, len(parsers))}
+	positions := map[string]int{}
+	for index, fileHeaders := range headers {
+		for _, header := range fileHeaders {
+			position, ok := positions[header.Header]
+			if !ok {
+				position = len(parser.headers) + 1
+				positions[header.Header] = position
+				parser.headers = append(parser.headers, header)
+			}
+			parser.columns[index] = append(parser.columns[index], position)
+		}
+	}
+	return parser
+}
+
+//Splits a line of the merged copy into the index of its file and the original line
+//Returns false if the line has no file index
+func splitSource(line string, files int) (int, string, bool) {
+	separator := strings.Index(line, sourceSeparator)
+	if separator == -1 {
+		return 0, line, false
+	}
+	index, err := strconv.Atoi(line[:separator])
+	if err != nil || index < 0 || index >= files {
+		return 0, line, false
+	}
+	return index, line[separator+len(sourceSeparator):], true
+}
+
+func (p mergedParser) parse(line string) []string {
+	index, text, ok := splitSource(line, len(p.parsers))
+	if !ok {
+		return separatorParser{}.parse(line)
+	}
+	row := p.parsers[index].parse(text)
+	if len(row) <= 1 {
+		return row
+	}
+	//Columns past the headers of the file are kept after the merged headers
+	merged := make([]string, len(p.headers)+1)
+	merged[0] = p.names[index]
+	for column, value := range row {
+		if column < len(p.columns[index]) {
+			merged[p.columns[index][column]] = value
+		} else {
+			merged = append(merged, value)
+		}
+	}
+	return merged
+}
+
+func (p mergedParser) isEntry(line string) bool {
+	index, text, ok := splitSource(line, len(p.parsers))
+	return ok && p.parsers[index].isEntry(text)
+}
+
+func (p mergedParser) formatDetails(entry string) string {
+	index, text, ok := splitSource(entry, len(p.parsers))
+	if !ok {
+		return entry
+	}
+	if formatter, ok := p.parsers[index].(detailsFormatter); ok {
+		return formatter.formatDetails(text)
+	}
+	return text
+}
+
+func (p mergedParser) text(line string) string {
+	_, text, _ := splitSource(line, len(p.parsers))
+	return text
+}
@@ -0,0 +1,172 @@
+package logreader
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func mergedLogReader(t *testing.T) (LogReader, func()) {
+	dir, cleanup := tempDir(t)
+	api := filepath.Join(dir, "api.log")
+	db := filepath.Join(dir, "db.log")
+	writeTestLog(t, api, "2010-11-11 10:00:01~Thread-1~com.api\n2010-11-11 10:00:04~Thread-1~com.api\n")
+	writeTestLog(t, db, "2010-11-11 10:00:02~Thread-2~com.db\njava.lang.IllegalStateException: Connection closed\n2010-11-11 10:00:03~Thread-2~com.db\n")
+
+	config := logreaderConfig(api, []int{19, 10, 10})
+	config.Files = []LogFile{{LogFile: api, Name: "api"}, {LogFile: db, Name: "db"}}
+	config.TimestampColumn = "Date"
+	config.TimestampLayout = "2006-01-02 15:04:05"
+	config.IndexCacheDir = filepath.Join(dir, "cache")
+	logReader := NewLogReader(config)
+	logReader.FileIndex = logReader.MergedIndex()
+	return logReader, cleanup
+}
+
+func TestLogReader_Merged_interleavesByTime(t *testing.T) {
+	logReader, cleanup := mergedLogReader(t)
+	defer cleanup()
+	defer logReader.Close()
+	logReader.SetCapacity(5)
+
+	expected := [][]string{
+		{"api", "2010-11-11 10:00:01", "Thread-1", "com.api"},
+		{"db", "2010-11-11 10:00:02", "Thread-2", "com.db"},
+		{"java.lang.IllegalStateException: Connection closed"},
+		{"db", "2010-11-11 10:00:03", "Thread-2", "com.db"},
+		{"api", "2010-11-11 10:00:04", "Thread-1", "com.api"},
+	}
+	if result := *logReader.Head(); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+	if headers := logReader.GetHeaders(); !reflect.DeepEqual(headers, []string{"Source", "Date", "Thread", "Package"}) {
+		t.Errorf("Expected the source column before the headers, got %s", headers)
+	}
+}
+
+func TestLogReader_Merged_tailsAllFiles(t *testing.T) {
+	logReader, cleanup := mergedLogReader(t)
+	defer cleanup()
+	defer logReader.Close()
+	logReader.SetCapacity(2)
+	logReader.Tail()
+
+	file, _ := os.OpenFile(logReader.FilePaths()[1], os.O_APPEND|os.O_WRONLY, 0644)
+	file.WriteString("2010-11-11 10:00:05~Thread-3~com.db\n")
+	file.Close()
+
+	expected := [][]string{
+		{"api", "2010-11-11 10:00:04", "Thread-1", "com.api"},
+		{"db", "2010-11-11 10:00:05", "Thread-3", "com.db"},
+	}
+	if result := *logReader.Tail(); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+}
+
+func TestLogReader_Merged_messageWithoutFileIndex(t *testing.T) {
+	logReader, cleanup := mergedLogReader(t)
+	defer cleanup()
+	defer logReader.Close()
+	logReader.SetCapacity(5)
+	logReader.Head()
+
+	if message := logReader.Message(2); message != "2010-11-11 10:00:02~Thread-2~com.db\njava.lang.IllegalStateException: Connection closed" {
+		t.Errorf("Expected the original lines of the entry, got %q", message)
+	}
+}
+
+func TestLogReader_Merged_insertsLaterEntriesByTime(t *testing.T) {
+	logReader, cleanup := mergedLogReader(t)
+	defer cleanup()
+	defer logReader.Close()
+	logReader.SetCapacity(10)
+	logReader.Head()
+
+	file, _ := os.OpenFile(logReader.FilePaths()[1], os.O_APPEND|os.O_WRONLY, 0644)
+	file.WriteString("restarting~Thread-2~com.db\n")
+	file.Close()
+	file, _ = os.OpenFile(logReader.FilePaths()[0], os.O_APPEND|os.O_WRONLY, 0644)
+	file.WriteString("2010-11-11 10:00:02~Thread-9~com.api\n")
+	file.Close()
+
+	//The untimed entry keeps the time of the entry before it in its file
+	expected := [][]string{
+		{"api", "2010-11-11 10:00:01", "Thread-1", "com.api"},
+		{"db", "2010-11-11 10:00:02", "Thread-2", "com.db"},
+		{"java.lang.IllegalStateException: Connection closed"},
+		{"api", "2010-11-11 10:00:02", "Thread-9", "com.api"},
+		{"db", "2010-11-11 10:00:03", "Thread-2", "com.db"},
+		{"db", "restarting", "Thread-2", "com.db"},
+		{"api", "2010-11-11 10:00:04", "Thread-1", "com.api"},
+	}
+	if result := *logReader.Tail(); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+}
+
+func TestLogReader_Merged_filesWithTheirOwnHeaders(t *testing.T) {
+	logReader, cleanup := mergedLogReader(t)
+	defer cleanup()
+	defer logReader.Close()
+	notes := filepath.Join(filepath.Dir(logReader.FilePaths()[0]), "notes.log")
+	writeTestLog(t, notes, "started~boot\n")
+	config := logReader.config
+	config.Files = append(config.Files, LogFile{LogFile: notes, Name: "notes", Headers: []Header{{Header: "Note", Size: 10}, {Header: "Thread", Size: 10}}})
+	logReader = NewLogReader(config)
+	defer logReader.Close()
+	logReader.FileIndex = logReader.MergedIndex()
+	logReader.SetCapacity(2)
+
+	if headers := logReader.GetHeaders(); !reflect.DeepEqual(headers, []string{"Source", "Date", "Thread", "Package", "Note"}) {
+		t.Errorf("Expected the headers of all files, got %s", headers)
+	}
+	expected := [][]string{
+		{"notes", "", "boot", "", "started"},
+		{"api", "2010-11-11 10:00:01", "Thread-1", "com.api", ""},
+	}
+	if result := *logReader.Head(); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+
+	//Files without a time place their new entries after the last entry merged
+	file, _ := os.OpenFile(notes, os.O_APPEND|os.O_WRONLY, 0644)
+	file.WriteString("stopped~shutdown\n")
+	file.Close()
+	expected = [][]string{
+		{"api", "2010-11-11 10:00:04", "Thread-1", "com.api", ""},
+		{"notes", "", "shutdown", "", "stopped"},
+	}
+	if result := *logReader.Tail(); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+}
+
+func TestLogReader_Merged_filteredCopyStartedOverIsNotRotated(t *testing.T) {
+	logReader, cleanup := mergedLogReader(t)
+	defer cleanup()
+	defer logReader.Close()
+	logReader.SetCapacity(2)
+	logReader.SetFilters([]Filter{filter(`com\.api`, false)})
+	logReader.Tail()
+	waitForFilters(&logReader)
+	logReader.Tail()
+
+	file, _ := os.OpenFile(logReader.FilePaths()[0], os.O_APPEND|os.O_WRONLY, 0644)
+	file.WriteString("2010-11-11 10:00:02~Thread-9~com.api\n")
+	file.Close()
+	logReader.Tail()
+	waitForFilters(&logReader)
+
+	expected := [][]string{
+		{"api", "2010-11-11 10:00:02", "Thread-9", "com.api"},
+		{"api", "2010-11-11 10:00:04", "Thread-1", "com.api"},
+	}
+	if result := *logReader.Tail(); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+	if notice := logReader.RotationNotice(); notice != "" {
+		t.Errorf("Expected no rotation notice, got %q", notice)
+	}
+}
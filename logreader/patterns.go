@@ -0,0 +1,161 @@
+package logreader
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+//Stands for the tokens that vary between the messages of a pattern
+const patternWildcard = "<*>"
+
+//Share of the tokens of a message that must equal those of a template for the message to join its pattern
+const patternSimilarity = 0.5
+
+//Messages that differ only in their variable tokens, such as IDs and numbers, mined with Drain
+type Pattern struct {
+	//The tokens of the messages separated by spaces, the tokens that vary are <*>
+	Template string
+	Count    int
+	//The first message of the pattern
+	Example string
+	tokens  []string
+}
+
+//Clusters messages into patterns like Drain, messages are split on spaces and grouped by their number of tokens and first token
+//A message joins the pattern with the template sharing most of its tokens, the tokens that differ become wildcards
+type patternMiner struct {
+	groups   map[string][]*Pattern
+	patterns []*Pattern
+}
+
+func newPatternMiner() *patternMiner {
+	return &patternMiner{groups: make(map[string][]*Pattern)}
+}
+
+//Adds the message to its pattern, creating the pattern if no template is similar enough
+func (m *patternMiner) add(message string) {
+	tokens := strings.Fields(message)
+	if len(tokens) == 0 {
+		return
+	}
+	for index, token := range tokens {
+		if strings.IndexFunc(token, unicode.IsDigit) != -1 {
+			tokens[index] = patternWildcard
+		}
+	}
+
+	key := fmt.Sprintf("%d %s", len(tokens), tokens[0])
+	var best *Pattern
+	bestSimilarity := 0.0
+	for _, pattern := range m.groups[key] {
+		if similarity := tokenSimilarity(pattern.tokens, tokens); similarity > bestSimilarity {
+			best, bestSimilarity = pattern, similarity
+		}
+	}
+
+	if best == nil || bestSimilarity < patternSimilarity {
+		best = &Pattern{Example: message, tokens: tokens}
+		m.groups[key] = append(m.groups[key], best)
+		m.patterns = append(m.patterns, best)
+	}
+	for index, token := range tokens {
+		if best.tokens[index] != token {
+			best.tokens[index] = patternWildcard
+		}
+	}
+	best.Count++
+}
+
+//Returns the share of the tokens of the message equal to the tokens of the template, wildcards of the template count as equal
+func tokenSimilarity(template []string, tokens []string) float64 {
+	equal := 0
+	for index, token := range tokens {
+		if template[index] == token || template[index] == patternWildcard {
+			equal++
+		}
+	}
+	return float64(equal) / float64(len(tokens))
+}
+
+//Returns the patterns with the most messages first, patterns of the same size in the order they first appear
+func (m *patternMiner) result() []Pattern {
+	patterns := make([]Pattern, len(m.patterns))
+	for index, pattern := range m.patterns {
+		patterns[index] = *pattern
+		patterns[index].Template = strings.Join(pattern.tokens, " ")
+	}
+	sort.SliceStable(patterns, func(i, j int) bool {
+		return patterns[i].Count > patterns[j].Count
+	})
+	return patterns
+}
+
+//Returns a query matching the column of the header against the template, such as Message~"^user \S+ logged in$"
+func (p Pattern) Query(header string) string {
+	tokens := strings.Fields(p.Template)
+	for index, token := range tokens {
+		if token == patternWildcard {
+			tokens[index] = `\S+`
+		} else {
+			tokens[index] = regexp.QuoteMeta(token)
+		}
+	}
+	expression := "^" + strings.Join(tokens, `\s+`) + "$"
+	return fmt.Sprintf(`%s~"%s"`, header, strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(expression))
+}
+
+//Mines the patterns of the column of the header in the entries of the active file as it is shown in the background, the header is case insensitive
+//done is called from the background with the patterns
+//Returns an error if the file has no column with the header
+func (l *LogReader) Patterns(header string, done func(patterns []Pattern)) error {
+	headers := l.headers()
+	column := -1
+	names := make([]string, len(headers))
+	for index, candidate := range headers {
+		names[index] = candidate.Header
+		if strings.EqualFold(candidate.Header, header) {
+			column = index
+		}
+	}
+	if column == -1 {
+		return fmt.Errorf("unknown header %q, expected one of %s", header, strings.Join(names, ", "))
+	}
+
+	file, err := l.openLogFile()
+	if err != nil {
+		return err
+	}
+	parser := l.parser()
+	go func() {
+		defer file.Close()
+		done(minePatterns(file, parser, column))
+	}()
+	return nil
+}
+
+//Mines the patterns of the column in the entries of the log from its start, see Patterns
+func minePatterns(file logSource, parser lineParser, column int) []Pattern {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil
+	}
+
+	miner := newPatternMiner()
+	reader := bufio.NewReader(file)
+	for {
+		data, err := reader.ReadString('\n')
+		if line := strings.TrimRight(data, "\r\n"); line != "" && parser.isEntry(line) {
+			if columns := parser.parse(line); column < len(columns) {
+				miner.add(strings.TrimSpace(columns[column]))
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	return miner.result()
+}
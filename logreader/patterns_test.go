@@ -0,0 +1,81 @@
+package logreader
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPatternMiner_templates(t *testing.T) {
+	miner := newPatternMiner()
+	for _, message := range []string{
+		"user 42 logged in from 10.0.0.1",
+		"connection to db-1 closed",
+		"user 7 logged in from 10.0.0.9",
+		"connection to db-2 closed",
+		"user 99 logged out",
+		"connection to cache closed",
+		"",
+	} {
+		miner.add(message)
+	}
+
+	var templates []string
+	var counts []int
+	for _, pattern := range miner.result() {
+		templates = append(templates, pattern.Template)
+		counts = append(counts, pattern.Count)
+	}
+	if expected := []string{"connection to <*> closed", "user <*> logged in from <*>", "user <*> logged out"}; !reflect.DeepEqual(templates, expected) {
+		t.Errorf("Expected %q, got %q", expected, templates)
+	}
+	if expected := []int{3, 2, 1}; !reflect.DeepEqual(counts, expected) {
+		t.Errorf("Expected the counts %v, got %v", expected, counts)
+	}
+	if example := miner.result()[1].Example; example != "user 42 logged in from 10.0.0.1" {
+		t.Errorf("Expected the first message as the example, got %q", example)
+	}
+}
+
+func TestPattern_Query(t *testing.T) {
+	pattern := Pattern{Template: `took <*> ms in "C:\tmp" (retry)`}
+	query, err := ParseQuery(pattern.Query("Message"), SearchOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error %v for %s", err, pattern.Query("Message"))
+	}
+	bound := query.bind(queryHeaders)
+	if !bound.matchColumns("", []string{"", "", "", "", `took 12 ms in "C:\tmp" (retry)`}) {
+		t.Errorf("Expected the query %s to match the messages of the template", pattern.Query("Message"))
+	}
+	if bound.matchColumns("", []string{"", "", "", "", `took 12 ms in "C:\tmp" (retry) again`}) {
+		t.Errorf("Expected the query %s to match the whole message", pattern.Query("Message"))
+	}
+}
+
+//Mines the patterns of the column of the active file and waits for them
+func waitForPatterns(logReader *LogReader, header string) ([]Pattern, error) {
+	patterns := make(chan []Pattern, 1)
+	if err := logReader.Patterns(header, func(found []Pattern) {
+		patterns <- found
+	}); err != nil {
+		return nil, err
+	}
+	return <-patterns, nil
+}
+
+func TestLogReader_Patterns(t *testing.T) {
+	path, cleanup := tempLog(t, "11/11/2010~Thread-1~com.test\n12/11/2010~Thread-2~com.test\n\tat com.test.Service.run(Service.java:20)\n13/11/2010~Thread-3~org.other\n")
+	defer cleanup()
+
+	logReader := NewLogReader(logreaderConfig(path, []int{10, 10, 10}))
+	defer logReader.Close()
+	patterns, err := waitForPatterns(&logReader, "thread")
+	if err != nil || len(patterns) != 1 || patterns[0].Template != "<*>" || patterns[0].Count != 3 {
+		t.Errorf("Expected the continuation line left out, got %+v %v", patterns, err)
+	}
+	if patterns, _ := waitForPatterns(&logReader, "Package"); len(patterns) != 2 || patterns[0].Count != 2 {
+		t.Errorf("Expected two packages, got %+v", patterns)
+	}
+	if _, err := waitForPatterns(&logReader, "Message"); err == nil {
+		t.Errorf("Expected an error for a header the file doesn't have")
+	}
+}
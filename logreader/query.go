@@ -0,0 +1,430 @@
+package logreader
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+//Matches the columns of the log entries against a query using the header names, such as
+//Severity=ERROR AND Thread~"Thread-[12]" AND NOT Package:com.test
+//Header=value equals the value, Header~value matches the regular expression and Header:value contains the value
+//A value without a header matches anywhere in the line like a search term, values with spaces or operators are quoted
+//NOT binds tighter than AND, AND binds tighter than OR, terms next to each other are joined with AND and parentheses group
+//Comparisons are case insensitive unless the options are case sensitive
+type Query struct {
+	root    queryNode
+	options SearchOptions
+}
+
+//A query bound to the headers of a file, see LogReader.bind
+type boundQuery struct {
+	*Query
+	columns map[string]int
+}
+
+//The line and columns of the log entry a query is evaluated against
+type queryEntry struct {
+	line    string
+	values  []string
+	columns map[string]int
+}
+
+type queryNode interface {
+	eval(entry queryEntry) bool
+	//Appends the patterns of the terms that aren't negated, used to highlight the matches
+	highlights(patterns []*regexp.Regexp) []*regexp.Regexp
+	//Appends the headers used by the comparisons
+	headers(names []string) []string
+}
+
+type andNode struct {
+	left, right queryNode
+}
+
+type orNode struct {
+	left, right queryNode
+}
+
+type notNode struct {
+	node queryNode
+}
+
+//Compares the column of a header to a value
+type comparisonNode struct {
+	header   string
+	operator byte
+	pattern  *regexp.Regexp
+	value    string
+	options  SearchOptions
+}
+
+//A value matched anywhere in the line
+type textNode struct {
+	matcher Matcher
+	pattern *regexp.Regexp
+}
+
+//Reads a query from its text
+type queryParser struct {
+	input   string
+	pos     int
+	options SearchOptions
+}
+
+//Parses the query, the options apply to the values without a header and the case sensitivity of the comparisons
+//Returns an error describing the first problem in the query
+func ParseQuery(query string, options SearchOptions) (*Query, error) {
+	parser := &queryParser{input: query, options: options}
+	parser.skipSpaces()
+	if parser.pos == len(parser.input) {
+		return nil, fmt.Errorf("invalid query: empty query")
+	}
+
+	root, err := parser.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid query: %v", err)
+	}
+	if parser.pos < len(parser.input) {
+		return nil, fmt.Errorf("invalid query: unexpected %q at %d", parser.input[parser.pos], parser.pos+1)
+	}
+
+	return &Query{root, options}, nil
+}
+
+//Matches the line as a single unparsed value, only the values without a header can match
+func (q *Query) Match(line string) bool {
+	return q.root.eval(queryEntry{line: line})
+}
+
+//Returns the matches of the values in the query that aren't negated, whether in their column or not
+func (q *Query) FindAll(text string) [][]int {
+	var matches [][]int
+	for _, pattern := range q.root.highlights(nil) {
+		matches = append(matches, pattern.FindAllStringIndex(text, -1)...)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i][0] < matches[j][0] || matches[i][0] == matches[j][0] && matches[i][1] > matches[j][1]
+	})
+
+	//Overlapping matches are highlighted once
+	var merged [][]int
+	for _, match := range matches {
+		if len(merged) > 0 && match[0] < merged[len(merged)-1][1] {
+			if match[1] > merged[len(merged)-1][1] {
+				merged[len(merged)-1][1] = match[1]
+			}
+			continue
+		}
+		merged = append(merged, match)
+	}
+	return merged
+}
+
+//Returns the headers used in the comparisons of the query
+func (q *Query) Headers() []string {
+	return q.root.headers(nil)
+}
+
+//Matches a row read from the log against the query, the headers name the columns of the row
+func (q *Query) MatchRow(headers []string, row []string) bool {
+	columns := make([]Header, len(headers))
+	for index, header := range headers {
+		columns[index] = Header{Header: header}
+	}
+	return q.bind(columns).matchColumns(strings.Join(row, " "), row)
+}
+
+//Binds the query to the headers of a file so its lines are matched column by column
+func (q *Query) bind(headers []Header) boundQuery {
+	columns := make(map[string]int, len(headers))
+	for index, header := range headers {
+		columns[strings.ToLower(header.Header)] = index
+	}
+	return boundQuery{q, columns}
+}
+
+//Checks that every header used in the query is one of the headers
+//Returns an error naming the first unknown header
+func (q *Query) check(headers []Header) error {
+	columns := q.bind(headers).columns
+	for _, name := range q.Headers() {
+		if _, ok := columns[strings.ToLower(name)]; !ok {
+			names := make([]string, len(headers))
+			for index, header := range headers {
+				names[index] = header.Header
+			}
+			return fmt.Errorf("unknown header %q, expected one of %s", name, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+func (q boundQuery) matchColumns(line string, values []string) bool {
+	return q.root.eval(queryEntry{line, values, q.columns})
+}
+
+//Returns the value of the column of the header, false if the entry has no such column
+func (e queryEntry) column(header string) (string, bool) {
+	index, ok := e.columns[strings.ToLower(header)]
+	if !ok || index >= len(e.values) {
+		return "", false
+	}
+	return strings.TrimSpace(e.values[index]), true
+}
+
+func (n andNode) eval(entry queryEntry) bool {
+	return n.left.eval(entry) && n.right.eval(entry)
+}
+
+func (n andNode) highlights(patterns []*regexp.Regexp) []*regexp.Regexp {
+	return n.right.highlights(n.left.highlights(patterns))
+}
+
+func (n andNode) headers(names []string) []string {
+	return n.right.headers(n.left.headers(names))
+}
+
+func (n orNode) eval(entry queryEntry) bool {
+	return n.left.eval(entry) || n.right.eval(entry)
+}
+
+func (n orNode) highlights(patterns []*regexp.Regexp) []*regexp.Regexp {
+	return n.right.highlights(n.left.highlights(patterns))
+}
+
+func (n orNode) headers(names []string) []string {
+	return n.right.headers(n.left.headers(names))
+}
+
+func (n notNode) eval(entry queryEntry) bool {
+	return !n.node.eval(entry)
+}
+
+func (n notNode) highlights(patterns []*regexp.Regexp) []*regexp.Regexp {
+	return patterns
+}
+
+func (n notNode) headers(names []string) []string {
+	return n.node.headers(names)
+}
+
+func (n comparisonNode) eval(entry queryEntry) bool {
+	value, ok := entry.column(n.header)
+	if !ok {
+		return false
+	}
+	if n.operator == '=' {
+		if n.options.CaseSensitive {
+			return value == n.value
+		}
+		return strings.EqualFold(value, n.value)
+	}
+	return n.pattern.MatchString(value)
+}
+
+func (n comparisonNode) highlights(patterns []*regexp.Regexp) []*regexp.Regexp {
+	return append(patterns, n.pattern)
+}
+
+func (n comparisonNode) headers(names []string) []string {
+	return append(names, n.header)
+}
+
+func (n textNode) eval(entry queryEntry) bool {
+	return n.matcher.Match(entry.line)
+}
+
+func (n textNode) highlights(patterns []*regexp.Regexp) []*regexp.Regexp {
+	return append(patterns, n.pattern)
+}
+
+func (n textNode) headers(names []string) []string {
+	return names
+}
+
+//Parses terms joined with OR
+func (p *queryParser) parseOr() (queryNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+//Parses terms joined with AND or following each other
+func (p *queryParser) parseAnd() (queryNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if !p.keyword("AND") && (p.pos == len(p.input) || p.input[p.pos] == ')' || p.peekKeyword("OR")) {
+			return left, nil
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+}
+
+func (p *queryParser) parseNot() (queryNode, error) {
+	if p.keyword("NOT") {
+		node, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{node}, nil
+	}
+	return p.parseTerm()
+}
+
+//Parses a comparison, a value on its own or a query in parentheses
+func (p *queryParser) parseTerm() (queryNode, error) {
+	if p.pos == len(p.input) {
+		return nil, fmt.Errorf("unexpected end of query")
+	}
+	switch p.input[p.pos] {
+	case '(':
+		p.pos++
+		p.skipSpaces()
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.pos == len(p.input) || p.input[p.pos] != ')' {
+			return nil, fmt.Errorf("missing ) at %d", p.pos+1)
+		}
+		p.pos++
+		p.skipSpaces()
+		return node, nil
+	case ')', '=', '~', ':':
+		return nil, fmt.Errorf("unexpected %q at %d", p.input[p.pos], p.pos+1)
+	}
+
+	quoted := p.input[p.pos] == '"'
+	text, err := p.word("()=~:")
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if quoted || p.pos == len(p.input) || !strings.ContainsRune("=~:", rune(p.input[p.pos])) {
+		return p.textTerm(text)
+	}
+
+	operator := p.input[p.pos]
+	p.pos++
+	p.skipSpaces()
+	if p.pos == len(p.input) || p.input[p.pos] == ')' {
+		return nil, fmt.Errorf("missing value after %s%c", text, operator)
+	}
+	value, err := p.word("()")
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	return p.comparison(text, operator, value)
+}
+
+//Creates the comparison of the column of the header with the value
+//Returns an error if the value of ~ isn't a valid regular expression
+func (p *queryParser) comparison(header string, operator byte, value string) (queryNode, error) {
+	expression := regexp.QuoteMeta(value)
+	if operator == '~' {
+		expression = value
+	}
+	if !p.options.CaseSensitive {
+		expression = `(?i)` + expression
+	}
+
+	pattern, err := regexp.Compile(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex for %s: %v", header, strings.TrimPrefix(err.Error(), "error parsing regexp: "))
+	}
+	return comparisonNode{header, operator, pattern, value, p.options}, nil
+}
+
+//Creates the term matching the value anywhere in the line with the search options
+func (p *queryParser) textTerm(value string) (queryNode, error) {
+	matcher, err := NewMatcher(value, SearchOptions{Regex: p.options.Regex, CaseSensitive: p.options.CaseSensitive, WholeWord: p.options.WholeWord})
+	if err != nil {
+		return nil, err
+	}
+	return textNode{matcher, matcher.(regexMatcher).pattern}, nil
+}
+
+//Reads a quoted string or a word ending before a space or one of the stop characters
+//A quoted string may contain \" and \\, any other backslash is kept for regular expressions
+//Returns an error if the quote isn't closed
+func (p *queryParser) word(stop string) (string, error) {
+	start := p.pos
+	if p.input[p.pos] != '"' {
+		for p.pos < len(p.input) {
+			character, width := utf8.DecodeRuneInString(p.input[p.pos:])
+			if unicode.IsSpace(character) || strings.ContainsRune(stop+`"`, character) {
+				break
+			}
+			p.pos += width
+		}
+		return p.input[start:p.pos], nil
+	}
+
+	var value strings.Builder
+	for p.pos++; p.pos < len(p.input); p.pos++ {
+		switch {
+		case p.input[p.pos] == '"':
+			p.pos++
+			return value.String(), nil
+		case p.input[p.pos] == '\\' && p.pos+1 < len(p.input) && strings.ContainsRune(`"\`, rune(p.input[p.pos+1])):
+			p.pos++
+		}
+		value.WriteByte(p.input[p.pos])
+	}
+	return "", fmt.Errorf("missing closing quote for the value at %d", start+1)
+}
+
+//Consumes the keyword if it is next, keywords are case insensitive
+func (p *queryParser) keyword(keyword string) bool {
+	if !p.peekKeyword(keyword) {
+		return false
+	}
+	p.pos += len(keyword)
+	p.skipSpaces()
+	return true
+}
+
+//Returns true if the keyword is the next word
+func (p *queryParser) peekKeyword(keyword string) bool {
+	end := p.pos + len(keyword)
+	if end > len(p.input) || !strings.EqualFold(p.input[p.pos:end], keyword) {
+		return false
+	}
+	if end == len(p.input) {
+		return true
+	}
+	next, _ := utf8.DecodeRuneInString(p.input[end:])
+	return unicode.IsSpace(next) || next == '('
+}
+
+//Skips the spaces before the next word, spaces are decoded as runes so multi byte characters aren't split
+func (p *queryParser) skipSpaces() {
+	for p.pos < len(p.input) {
+		character, width := utf8.DecodeRuneInString(p.input[p.pos:])
+		if !unicode.IsSpace(character) {
+			return
+		}
+		p.pos += width
+	}
+}
@@ -0,0 +1,170 @@
+package logreader
+
+import (
+	"reflect"
+	"testing"
+)
+
+var queryHeaders = []Header{{Header: "Date"}, {Header: "Thread"}, {Header: "Severity"}, {Header: "Package"}, {Header: "Message"}}
+
+func TestQuery_matchColumns(t *testing.T) {
+	columns := []string{"11/11/2010", "Thread-1", "ERROR", "com.test.Service", "Connection refused by db-1"}
+	cases := []struct {
+		query string
+		match bool
+	}{
+		{"Severity=ERROR", true},
+		{"severity=error", true},
+		{"Severity=ERR", false},
+		{"Severity:ERR", true},
+		{`Thread~"Thread-[12]$"`, true},
+		{`Thread~"Thread-[23]$"`, false},
+		{"Package:com.test", true},
+		{"NOT Package:com.test", false},
+		{"Severity=ERROR AND Thread=Thread-1", true},
+		{"Severity=ERROR Thread=Thread-2", false},
+		{"Severity=WARN OR Severity=ERROR", true},
+		{"Severity=WARN OR Severity=INFO AND Thread=Thread-1", false},
+		{"(Severity=WARN OR Severity=ERROR) AND NOT Package:other", true},
+		{"NOT (Severity=WARN OR Severity=ERROR)", false},
+		{`Message:"refused by"`, true},
+		{"refused", true},
+		{"Severity=refused", false},
+		{`"Severity=ERROR"`, false},
+		{"Unknown=ERROR", false},
+	}
+	for _, c := range cases {
+		query, err := ParseQuery(c.query, SearchOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", c.query, err)
+		}
+		if actual := query.bind(queryHeaders).matchColumns("11/11/2010~Thread-1~ERROR~com.test.Service~Connection refused by db-1", columns); actual != c.match {
+			t.Errorf("Expected %q to be %v", c.query, c.match)
+		}
+	}
+}
+
+func TestQuery_caseSensitive(t *testing.T) {
+	query, _ := ParseQuery("Severity=error OR Thread:thread", SearchOptions{CaseSensitive: true})
+	if query.bind(queryHeaders).matchColumns("", []string{"", "Thread-1", "ERROR"}) {
+		t.Errorf("Expected case sensitive comparisons")
+	}
+}
+
+func TestQuery_continuationLines(t *testing.T) {
+	query, _ := ParseQuery("Severity=ERROR OR NullPointerException", SearchOptions{})
+	line := "java.lang.NullPointerException: null"
+	if !query.bind(queryHeaders).matchColumns(line, []string{line}) {
+		t.Errorf("Expected a value without a header to match a continuation line")
+	}
+	if !query.Match(line) {
+		t.Errorf("Expected the unbound query to match the value anywhere in the line")
+	}
+}
+
+func TestQuery_parseErrors(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"Severity=",
+		"Severity=ERROR AND",
+		"(Severity=ERROR",
+		"Severity=ERROR)",
+		`Thread~"Thread-(`,
+		`Message:"unterminated`,
+		"=ERROR",
+		"NOT",
+	} {
+		if _, err := ParseQuery(input, SearchOptions{}); err == nil {
+			t.Errorf("Expected %q to be rejected", input)
+		}
+	}
+}
+
+func TestQuery_quotedValues(t *testing.T) {
+	query, err := ParseQuery(`Message="say \"hi\"" OR Message~"\d+ ms"`, SearchOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	bound := query.bind(queryHeaders)
+	if !bound.matchColumns("", []string{"", "", "", "", `say "hi"`}) || !bound.matchColumns("", []string{"", "", "", "", "took 12 ms"}) {
+		t.Errorf("Expected the quoted values to be unescaped")
+	}
+}
+
+func TestQuery_nonASCIIValues(t *testing.T) {
+	//à and Ņ end with the bytes 0x85 and 0xA0, which aren't spaces inside a rune
+	query, err := ParseQuery("Message:voilà AND Thread=Ņ\u00a0AND Severity=ERROR", SearchOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	bound := query.bind(queryHeaders)
+	if !bound.matchColumns("", []string{"", "Ņ", "ERROR", "", "et voilà"}) {
+		t.Errorf("Expected the non ASCII values to be read whole")
+	}
+	if bound.matchColumns("", []string{"", "Ņ", "ERROR", "", "et voil"}) {
+		t.Errorf("Expected the value not to be split in the middle of a character")
+	}
+}
+
+func TestQuery_Headers_check(t *testing.T) {
+	query, _ := ParseQuery("Severity=ERROR AND (thread:1 OR NOT Host=a) AND text", SearchOptions{})
+	if expected := []string{"Severity", "thread", "Host"}; !reflect.DeepEqual(query.Headers(), expected) {
+		t.Errorf("Expected the headers %v, got %v", expected, query.Headers())
+	}
+	if err := query.check(queryHeaders); err == nil {
+		t.Errorf("Expected Host to be reported as an unknown header")
+	}
+	query, _ = ParseQuery("Severity=ERROR AND thread:1", SearchOptions{})
+	if err := query.check(queryHeaders); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestQuery_FindAll(t *testing.T) {
+	query, _ := ParseQuery("Severity=ERROR AND NOT Thread=Thread-2 AND (refused OR ERROR by)", SearchOptions{})
+	text := "ERROR Thread-1 Connection refused by db"
+	expected := [][]int{{0, 5}, {26, 33}, {34, 36}}
+	if actual := query.FindAll(text); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+}
+
+func TestLogReader_SearchWith_query(t *testing.T) {
+	input := "../test_logs/TestLogReader_Search.log"
+	logReader := NewLogReader(logreaderConfig(input, []int{10, 10, 10}))
+	logReader.SetCapacity(1)
+	//Thread-4 is the first entry of the date, the NOT skips it
+	matcher, _ := NewMatcher("Date=14/11/2010 AND NOT Thread=Thread-4", SearchOptions{Query: true})
+	if err := logReader.CheckMatcher(matcher); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	expected := [][]string{{"14/11/2010", "Thread-5", "com.test"}}
+	if actual, _ := logReader.SearchWith(matcher, -1); !reflect.DeepEqual(*actual, expected) {
+		t.Errorf("Expected %s, got %s", expected, *actual)
+	}
+
+	unknown, _ := NewMatcher("Severity=ERROR", SearchOptions{Query: true})
+	if err := logReader.CheckMatcher(unknown); err == nil {
+		t.Errorf("Expected an error for a header the file doesn't have")
+	}
+}
+
+func TestLogReader_SetFilters_query(t *testing.T) {
+	input := "../test_logs/TestLogReader_Tail_input.log"
+	logReader := NewLogReader(logreaderConfig(input, []int{10, 10, 10}))
+	defer logReader.Close()
+	logReader.SetCapacity(2)
+	matcher, _ := NewMatcher(`Thread~"-[12]$" OR Date=15/11/2010`, SearchOptions{Query: true})
+	logReader.SetFilters([]Filter{{Matcher: matcher}})
+	waitForFilters(&logReader)
+
+	expected := [][]string{{"11/11/2010", "Thread-1", "com.test"}, {"12/11/2010", "Thread-2", "com.test"}}
+	if result := *logReader.Head(); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+	expected = [][]string{{"12/11/2010", "Thread-2", "com.test"}, {"15/11/2010", "Thread-5", "com.test"}}
+	if result := *logReader.Tail(); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+}
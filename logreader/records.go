@@ -0,0 +1,206 @@
+package logreader
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+//Joins the lines of a record, log lines don't contain it
+const recordSeparator = "\x1f"
+
+//Header of the column counting the continuation lines of a collapsed record
+const LinesHeader = "Lines"
+
+//Reads the lines of an entry joined by recordSeparator, the rows of the collapsed view and the text the filters match
+type recordParser struct {
+	parser lineParser
+}
+
+//Reads the records of the log starting at the offset, each an entry line followed by its continuation lines
+//Continuation lines at the offset are read as a record of their own, such as those before the first entry of the file
+//visit is called with the lines of every record and the offset right after each line, the reading stops once visit returns false
+func readRecords(file logSource, parser lineParser, offset int, visit func(lines []string, ends []int) bool) {
+	if _, err := file.Seek(int64(offset), io.SeekStart); err != nil {
+		return
+	}
+
+	reader := bufio.NewReader(file)
+	var lines []string
+	var ends []int
+	for {
+		data, err := reader.ReadString('\n')
+		if len(data) > 0 {
+			line := strings.TrimRight(data, "\r\n")
+			if parser.isEntry(line) && len(lines) > 0 {
+				if !visit(lines, ends) {
+					return
+				}
+				lines, ends = nil, nil
+			}
+			offset += len(data)
+			lines = append(lines, line)
+			ends = append(ends, offset)
+		}
+		if err != nil {
+			break
+		}
+	}
+	if len(lines) > 0 {
+		visit(lines, ends)
+	}
+}
+
+//Reads the page of whole records starting at the offset, see readRecords
+//Every line is a row and the page ends before the first record that doesn't fit, a record longer than the page shows its first lines
+//Collapsed every record is a row counting its continuation lines, see recordParser
+//Returns the parsed rows and the offset right after the page
+func readPage(file logSource, parser lineParser, capacity int, collapsed bool, offset int) ([][]string, int) {
+	rows := [][]string{}
+	end := offset
+	if capacity < 1 {
+		return rows, end
+	}
+	readRecords(file, parser, offset, func(lines []string, ends []int) bool {
+		if collapsed {
+			rows = append(rows, recordParser{parser}.parse(strings.Join(lines, recordSeparator)))
+			end = ends[len(ends)-1]
+			return len(rows) < capacity
+		}
+		if len(rows) > 0 && len(rows)+len(lines) > capacity {
+			return false
+		}
+		if len(lines) > capacity {
+			lines, ends = lines[:capacity], ends[:capacity]
+		}
+		for _, line := range lines {
+			rows = append(rows, parser.parse(line))
+		}
+		end = ends[len(ends)-1]
+		return len(rows) < capacity
+	})
+	return rows, end
+}
+
+//Returns the start of the page of whole records ending at the offset, the offset must be the start of a line
+//As many records as fit are read back, a record longer than the page shows its last lines, see readPage
+func pageStartBefore(file logSource, parser lineParser, index *lineIndex, capacity int, collapsed bool, offset int) int {
+	//An offset past the end of the file is left over from before the file was truncated
+	if fileInfo, err := file.Stat(); err == nil && offset > int(fileInfo.Size()) {
+		offset = int(fileInfo.Size())
+	}
+	lines := newBackwardLines(file, offset, index)
+	start, lineStart := offset, offset
+	//The rows of the records read back and the lines read of the record before them
+	rows, recordLines := 0, 0
+	for rows < capacity {
+		line, previous, ok := lines.previous()
+		if !ok {
+			break
+		}
+		recordLines++
+		if !collapsed && rows+recordLines > capacity {
+			if rows == 0 {
+				start = lineStart
+			}
+			break
+		}
+		lineStart = previous
+		if parser.isEntry(line) || lineStart == 0 {
+			if collapsed {
+				rows++
+			} else {
+				rows += recordLines
+			}
+			start, recordLines = lineStart, 0
+		}
+	}
+	return start
+}
+
+//Returns the start of the record holding the line starting at the offset
+//With a limit the record must start less than limit lines before the line, the start of the line is returned otherwise
+func recordStart(file logSource, parser lineParser, index *lineIndex, offset int, limit int) int {
+	data, _, _ := head(file, 1, offset)
+	if len(data) == 0 || parser.isEntry(data[0]) {
+		return offset
+	}
+
+	lines := newBackwardLines(file, offset, index)
+	for count := 1; limit == 0 || count < limit; count++ {
+		line, start, ok := lines.previous()
+		if !ok {
+			break
+		}
+		if parser.isEntry(line) || start == 0 {
+			return start
+		}
+	}
+	return offset
+}
+
+//Counts the records starting after the record at the start offset up to the line at the offset
+func countRecords(file logSource, parser lineParser, start int, offset int) int {
+	records := 0
+	readRecords(file, parser, start, func(lines []string, ends []int) bool {
+		if ends[len(ends)-1] > offset {
+			return false
+		}
+		records++
+		return true
+	})
+	return records
+}
+
+//Parses the first line of the record, entries get a column with the number of continuation lines
+func (p recordParser) parse(record string) []string {
+	lines := strings.Split(record, recordSeparator)
+	columns := p.parser.parse(lines[0])
+	if !p.parser.isEntry(lines[0]) {
+		return columns
+	}
+
+	count := ""
+	if len(lines) > 1 {
+		count = fmt.Sprintf("+%d", len(lines)-1)
+	}
+	return append(columns, count)
+}
+
+//Every record is a whole entry
+func (p recordParser) isEntry(record string) bool {
+	return true
+}
+
+//Returns the log text of the lines of the record
+func (p recordParser) text(record string) string {
+	lines := strings.Split(record, recordSeparator)
+	for index, line := range lines {
+		lines[index] = logText(p.parser, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+//Formats the first line of the record as its parser does, followed by its continuation lines
+func (p recordParser) formatDetails(record string) string {
+	lines := strings.Split(record, recordSeparator)
+	first := logText(p.parser, lines[0])
+	if formatter, ok := p.parser.(detailsFormatter); ok && p.parser.isEntry(lines[0]) {
+		first = formatter.formatDetails(lines[0])
+	}
+
+	details := []string{first}
+	for _, line := range lines[1:] {
+		details = append(details, logText(p.parser, line))
+	}
+	return strings.Join(details, "\n")
+}
+
+func (p recordParser) extraHeaders() []Header {
+	var headers []Header
+	if extra, ok := p.parser.(extraColumns); ok {
+		headers = append(headers, extra.extraHeaders()...)
+	}
+	return append(headers, Header{Header: LinesHeader, Size: 6})
+}
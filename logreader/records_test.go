@@ -0,0 +1,249 @@
+package logreader
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLogReader_SetCollapsed_groupsContinuationLines(t *testing.T) {
+	input := "../test_logs/TestLogReader_Navigate_withstacktrace.log"
+	logReader := NewLogReader(logreaderConfig(input, []int{10, 10, 10}))
+	defer logReader.Close()
+	logReader.SetCapacity(3)
+	logReader.SetCollapsed(true)
+
+	expected := [][]string{
+		{"16/11/2010", "Thread-6", "com.test", ""},
+		{"17/11/2010", "Thread-7", "com.test", ""},
+		{"18/11/2010", "Thread-8", "com.test", ""},
+	}
+	if result := *logReader.Tail(); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+	expected = [][]string{
+		{"14/11/2010", "Thread-11", "com.test", ""},
+		{"14/11/2010", "Thread-12", "com.test", "+43"},
+		{"15/11/2010", "Thread-5", "com.test", ""},
+	}
+	if result := *logReader.PageUp(); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+	if headers := logReader.GetHeaders(); headers[len(headers)-1] != LinesHeader {
+		t.Errorf("Expected the lines column, got %v", headers)
+	}
+
+	details := logReader.Message(2)
+	if !strings.HasPrefix(details, "14/11/2010~Thread-12~com.test\njava.lang.IllegalStateException") || strings.Count(details, "\n") != 43 {
+		t.Errorf("Expected the whole record in the details, got %q", details)
+	}
+
+	logReader.SetCollapsed(false)
+	if result := *logReader.Tail(); len(result[0]) != 3 {
+		t.Errorf("Expected the lines column to be gone once expanded, got %s", result)
+	}
+}
+
+func TestLogReader_SetCollapsed_searchesWholeRecords(t *testing.T) {
+	input := "../test_logs/TestLogReader_Navigate_withstacktrace.log"
+	logReader := NewLogReader(logreaderConfig(input, []int{10, 10, 10}))
+	defer logReader.Close()
+	logReader.SetCapacity(1)
+	logReader.SetCollapsed(true)
+	logReader.SetFilters([]Filter{filter(`MissingBackpressureException`, false)})
+	waitForFilters(&logReader)
+
+	expected := [][]string{{"14/11/2010", "Thread-12", "com.test", "+43"}}
+	if result := *logReader.Head(); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+
+	logReader.SetFilters(nil)
+	matcher, _ := NewMatcher("OnErrorNotImplementedException", SearchOptions{})
+	if result, _ := logReader.SearchWith(matcher, -1); !reflect.DeepEqual(*result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, *result)
+	}
+}
+
+func TestLogReader_SetCollapsed_rewritesTheLastRecord(t *testing.T) {
+	path, cleanup := tempLog(t, "11/11/2010~Thread-1~com.test\n12/11/2010~Thread-2~com.test\n")
+	defer cleanup()
+
+	logReader := NewLogReader(logreaderConfig(path, []int{10, 10, 10}))
+	defer logReader.Close()
+	logReader.SetCapacity(2)
+	logReader.SetCollapsed(true)
+	logReader.Tail()
+
+	file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	file.WriteString("java.lang.NullPointerException\n\tat com.test.Service.run(Service.java:10)\n")
+	file.Close()
+
+	expected := [][]string{{"11/11/2010", "Thread-1", "com.test", ""}, {"12/11/2010", "Thread-2", "com.test", "+2"}}
+	if result := *logReader.Tail(); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+
+	file, _ = os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	file.WriteString("\tat com.test.Main.main(Main.java:5)\n13/11/2010~Thread-3~com.test\n")
+	file.Close()
+
+	expected = [][]string{{"12/11/2010", "Thread-2", "com.test", "+3"}, {"13/11/2010", "Thread-3", "com.test", ""}}
+	if result := *logReader.Tail(); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+}
+
+func TestLogReader_entryStart(t *testing.T) {
+	path, cleanup := tempLog(t, "11/11/2010~Thread-1~com.test\n  retrying~Thread-1~com.test\n12/11/2010~Thread-2~com.test\n")
+	defer cleanup()
+
+	config := logreaderConfig(path, []int{10, 10, 10})
+	config.EntryStart = `^\d{2}/\d{2}/\d{4}~`
+	logReader := NewLogReader(config)
+	defer logReader.Close()
+	logReader.SetCapacity(2)
+	logReader.SetCollapsed(true)
+
+	expected := [][]string{{"11/11/2010", "Thread-1", "com.test", "+1"}, {"12/11/2010", "Thread-2", "com.test", ""}}
+	if result := *logReader.Head(); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+
+	config.EntryStart = "("
+	if _, err := newLineParser(config); err == nil {
+		t.Errorf("Expected an invalid entry start to be rejected")
+	}
+}
+
+func TestLogReader_SetCollapsed_filtersTheRewrittenRecord(t *testing.T) {
+	path, cleanup := tempLog(t, "11/11/2010~Thread-1~com.test\n12/11/2010~Thread-2~com.test\n")
+	defer cleanup()
+
+	logReader := NewLogReader(logreaderConfig(path, []int{10, 10, 10}))
+	defer logReader.Close()
+	logReader.SetCapacity(2)
+	logReader.SetCollapsed(true)
+	logReader.SetFilters([]Filter{filter(`Thread-1~|NullPointerException`, false)})
+	logReader.Tail()
+
+	file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	file.WriteString("java.lang.NullPointerException\n")
+	file.Close()
+	waitForFilters(&logReader)
+
+	expected := [][]string{{"11/11/2010", "Thread-1", "com.test", ""}, {"12/11/2010", "Thread-2", "com.test", "+1"}}
+	if result := *logReader.Tail(); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+	matcher, _ := NewMatcher("Thread", SearchOptions{})
+	if above, below := matchesAround(&logReader, matcher); above != 0 || below != 0 {
+		t.Errorf("Expected both records on the page, got %d above and %d below", above, below)
+	}
+}
+
+func TestLogReader_PageDown_keepsRecordsWhole(t *testing.T) {
+	input := "../test_logs/TestLogReader_Navigate_withstacktrace.log"
+	logReader := NewLogReader(logreaderConfig(input, []int{10, 10, 10}))
+	defer logReader.Close()
+	logReader.SetCapacity(3)
+	logReader.SeekLine(10)
+
+	//The stack trace of Thread-12 doesn't fit after Thread-11, the page ends before it
+	expected := [][]string{{"14/11/2010", "Thread-10", "com.test"}, {"14/11/2010", "Thread-11", "com.test"}}
+	if result := *logReader.Refresh(); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+	//A record longer than the page is shown from its first lines and paged through
+	if result := *logReader.PageDown(); result[0][1] != "Thread-12" || len(result) != 3 {
+		t.Errorf("Expected the page to start with Thread-12, got %s", result)
+	}
+	if result := *logReader.PageDown(); result[0][0] != "       at android.os.Handler.handleCallback(Handler.java:733)" {
+		t.Errorf("Expected the next lines of the stack trace, got %s", result)
+	}
+	if result := *logReader.PageUp(); result[0][1] != "Thread-12" {
+		t.Errorf("Expected the first lines of the stack trace, got %s", result)
+	}
+}
+
+func TestLogReader_Down_movesByRecord(t *testing.T) {
+	input := "../test_logs/TestLogReader_Navigate_withstacktrace.log"
+	logReader := NewLogReader(logreaderConfig(input, []int{10, 10, 10}))
+	defer logReader.Close()
+	logReader.SetCapacity(3)
+	logReader.SeekLine(12)
+
+	expected := [][]string{{"15/11/2010", "Thread-5", "com.test"}, {"16/11/2010", "Thread-6", "com.test"}, {"17/11/2010", "Thread-7", "com.test"}}
+	if result := *logReader.Down(); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+	if result := *logReader.Up(); result[0][1] != "Thread-12" {
+		t.Errorf("Expected the page to start with Thread-12 again, got %s", result)
+	}
+	if line := logReader.CurrentLine(); line != 12 {
+		t.Errorf("Expected the page to start at line 12, got %d", line)
+	}
+	//A move within the stack trace moves to the next entry
+	if result := *logReader.SeekRelative(1); result[0][1] != "Thread-5" {
+		t.Errorf("Expected the page to start with Thread-5, got %s", result)
+	}
+}
+
+func TestLogReader_SeekLine_startsAtTheRecord(t *testing.T) {
+	input := "../test_logs/TestLogReader_Navigate_withstacktrace.log"
+	logReader := NewLogReader(logreaderConfig(input, []int{10, 10, 10}))
+	defer logReader.Close()
+	logReader.SetCapacity(5)
+
+	if result := *logReader.SeekLine(14); result[0][1] != "Thread-12" {
+		t.Errorf("Expected the page to start with the entry of line 14, got %s", result)
+	}
+	//The entry of line 30 starts too far before it to show it on the page
+	if logReader.SeekLine(30); logReader.CurrentLine() != 30 {
+		t.Errorf("Expected the page to start at line 30, got %d", logReader.CurrentLine())
+	}
+
+	logReader.SetCollapsed(true)
+	expected := [][]string{{"14/11/2010", "Thread-12", "com.test", "+43"}, {"15/11/2010", "Thread-5", "com.test", ""}}
+	if result := *logReader.Refresh(); !reflect.DeepEqual(result[:2], expected) {
+		t.Errorf("Expected the position to be kept from the start of the entry, got %s", result)
+	}
+}
+
+func TestLogReader_SearchWith_showsTheRecordOfTheMatch(t *testing.T) {
+	input := "../test_logs/TestLogReader_Navigate_withstacktrace.log"
+	logReader := NewLogReader(logreaderConfig(input, []int{10, 10, 10}))
+	defer logReader.Close()
+	logReader.SetCapacity(20)
+	matcher, _ := NewMatcher("OnErrorNotImplementedException", SearchOptions{})
+
+	result, location := logReader.SearchWith(matcher, -1)
+	if (*result)[0][1] != "Thread-12" || location != 12 {
+		t.Errorf("Expected the match 12 lines into the page of Thread-12, got %d in %s", location, *result)
+	}
+
+	logReader.SetCollapsed(true)
+	logReader.Head()
+	result, location = logReader.SearchWith(matcher, -1)
+	if (*result)[location][1] != "Thread-12" {
+		t.Errorf("Expected the match on the row of Thread-12, got %d in %s", location, *result)
+	}
+	if _, next := logReader.SearchWith(matcher, location); next != location {
+		t.Errorf("Expected the only matching record to be found again, got %d", next)
+	}
+}
+
+func TestLogReader_SetFilters_matchesWholeRecords(t *testing.T) {
+	input := "../test_logs/TestLogReader_Navigate_withstacktrace.log"
+	logReader := NewLogReader(logreaderConfig(input, []int{10, 10, 10}))
+	defer logReader.Close()
+	logReader.SetCapacity(2)
+	logReader.SetFilters([]Filter{filter(`MissingBackpressureException`, false)})
+	waitForFilters(&logReader)
+
+	expected := [][]string{{"14/11/2010", "Thread-12", "com.test"}, {"java.lang.IllegalStateException: Exception thrown on Scheduler.Worker thread. Add `onError` handling."}}
+	if result := *logReader.Head(); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+}
@@ -0,0 +1,217 @@
+package logreader
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//Matches the rotation number of logrotate style names such as app.log.3 or app.log.3.gz
+var rotationNumber = regexp.MustCompile(`\.(\d+)(\.(gz|bz2|zst))?$`)
+
+//A family of rotated log files read as one continuous stream, oldest file first
+//A newline is added after any file that doesn't end with one so that lines never span two files
+type rotatedFamily struct {
+	files    []*os.File
+	starts   []int64
+	sizes    []int64
+	padded   []bool
+	size     int64
+	modTime  time.Time
+	position int64
+}
+
+//Describes the whole family as a single file
+type rotatedFamilyInfo struct {
+	os.FileInfo
+	size    int64
+	modTime time.Time
+}
+
+func (i rotatedFamilyInfo) Size() int64 {
+	return i.size
+}
+
+func (i rotatedFamilyInfo) ModTime() time.Time {
+	return i.modTime
+}
+
+//Opens the log file together with its rotated siblings matching the glob, compressed siblings are decompressed
+//The family is read at the offsets of its files, so the decompressed copies are opened once complete
+//The glob is relative to the directory of the log file unless it contains a directory itself
+//Returns an error if the active log file cannot be opened
+func openRotatedFamily(logFile string, glob string, decompressed *decompressionCache) (*rotatedFamily, error) {
+	if !strings.ContainsRune(glob, filepath.Separator) {
+		glob = filepath.Join(filepath.Dir(logFile), glob)
+	}
+	siblings, err := filepath.Glob(glob)
+	if err != nil {
+		return nil, err
+	}
+
+	family := &rotatedFamily{}
+	for _, path := range rotationOrder(logFile, siblings) {
+		file, err := decompressed.openComplete(path)
+		if err != nil {
+			if path == filepath.Clean(logFile) {
+				family.Close()
+				return nil, err
+			}
+			//The sibling might have been rotated away since the glob ran
+			continue
+		}
+		fileInfo, err := file.Stat()
+		if err != nil {
+			file.Close()
+			continue
+		}
+		family.add(file, fileInfo)
+	}
+
+	return family, nil
+}
+
+//Sorts the rotated siblings from the oldest to the newest, the active log file is always the last one
+//Numbered siblings (app.log.1, app.log.2.gz) are older the higher their number, other names (app.log-20200101) sort by name
+func rotationOrder(logFile string, siblings []string) []string {
+	active := filepath.Clean(logFile)
+	var rotated []string
+	for _, sibling := range siblings {
+		if filepath.Clean(sibling) != active {
+			rotated = append(rotated, filepath.Clean(sibling))
+		}
+	}
+
+	number := func(path string) int {
+		match := rotationNumber.FindStringSubmatch(path)
+		if match == nil {
+			return -1
+		}
+		n, _ := strconv.Atoi(match[1])
+		return n
+	}
+	sort.SliceStable(rotated, func(i, j int) bool {
+		ni, nj := number(rotated[i]), number(rotated[j])
+		if ni == -1 && nj == -1 {
+			return rotated[i] < rotated[j]
+		}
+		if ni == -1 || nj == -1 {
+			return ni == -1
+		}
+		return ni > nj
+	})
+
+	return append(rotated, active)
+}
+
+//Appends a file to the end of the stream
+func (f *rotatedFamily) add(file *os.File, fileInfo os.FileInfo) {
+	size := fileInfo.Size()
+	padded := false
+	if size > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, size-1); err == nil && last[0] != '\n' {
+			padded = true
+			size++
+		}
+	}
+
+	f.files = append(f.files, file)
+	f.starts = append(f.starts, f.size)
+	f.sizes = append(f.sizes, size)
+	f.padded = append(f.padded, padded)
+	f.size += size
+	if fileInfo.ModTime().After(f.modTime) {
+		f.modTime = fileInfo.ModTime()
+	}
+}
+
+//Reads across file boundaries so that the buffer is filled unless the end of the family is reached
+func (f *rotatedFamily) Read(p []byte) (int, error) {
+	if f.position >= f.size {
+		return 0, io.EOF
+	}
+
+	read := 0
+	for read < len(p) && f.position < f.size {
+		n, err := f.readPart(p[read:])
+		read += n
+		if err != nil {
+			return read, err
+		}
+	}
+
+	return read, nil
+}
+
+//Reads from the file containing the current position, stopping at the end of that file
+func (f *rotatedFamily) readPart(p []byte) (int, error) {
+	index := sort.Search(len(f.starts), func(i int) bool {
+		return f.starts[i]+f.sizes[i] > f.position
+	})
+	offset := f.position - f.starts[index]
+	remaining := f.sizes[index] - offset
+	if int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+
+	var n int
+	var err error
+	if f.padded[index] && offset == f.sizes[index]-1 {
+		p[0] = '\n'
+		n = 1
+	} else {
+		if f.padded[index] && int64(len(p)) == remaining {
+			p = p[:len(p)-1]
+		}
+		n, err = f.files[index].ReadAt(p, offset)
+		if err == io.EOF && n > 0 {
+			err = nil
+		}
+	}
+	f.position += int64(n)
+
+	return n, err
+}
+
+func (f *rotatedFamily) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.position
+	case io.SeekEnd:
+		offset += f.size
+	default:
+		return f.position, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return f.position, errors.New("negative position")
+	}
+
+	f.position = offset
+	return offset, nil
+}
+
+//Returns the combined size of the family and the modification time of the most recently changed file
+func (f *rotatedFamily) Stat() (os.FileInfo, error) {
+	active := f.files[len(f.files)-1]
+	fileInfo, err := active.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	return rotatedFamilyInfo{fileInfo, f.size, f.modTime}, nil
+}
+
+func (f *rotatedFamily) Close() error {
+	for _, file := range f.files {
+		file.Close()
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package logreader
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func rotatedReaderConfig() LogReaderConfig {
+	config := logreaderConfig("../test_logs/TestLogReader_Rotated/app.log", []int{10, 10, 10})
+	config.Files[0].RotationGlob = "app.log*"
+	return config
+}
+
+func TestRotatedFamily_rotationOrder(t *testing.T) {
+	siblings := []string{"logs/app.log", "logs/app.log.1", "logs/app.log.10.gz", "logs/app.log.2.gz", "logs/app.log-20200102", "logs/app.log-20200101"}
+	expected := []string{"logs/app.log-20200101", "logs/app.log-20200102", "logs/app.log.10.gz", "logs/app.log.2.gz", "logs/app.log.1", "logs/app.log"}
+	actual := rotationOrder("logs/app.log", siblings)
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %s, got %s", expected, actual)
+	}
+}
+
+func TestRotatedFamily_readsOneContinuousStream(t *testing.T) {
+	family, err := openRotatedFamily("../test_logs/TestLogReader_Rotated/app.log", "app.log*", newDecompressionCache(nil))
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	defer family.Close()
+
+	content, _ := ioutil.ReadAll(family)
+	expected := "01/11/2010~Thread-1~com.test\n02/11/2010~Thread-2~com.test\n" +
+		"03/11/2010~Thread-3~com.test\n04/11/2010~Thread-4~com.test\n" +
+		"05/11/2010~Thread-5~com.test\n06/11/2010~Thread-6~com.test\n" +
+		"07/11/2010~Thread-7~com.test\n08/11/2010~Thread-8~com.test\n"
+	if string(content) != expected {
+		t.Errorf("Expected %q, got %q", expected, string(content))
+	}
+	if fileInfo, _ := family.Stat(); fileInfo.Size() != int64(len(expected)) {
+		t.Errorf("Expected the family size to be %d, got %d", len(expected), fileInfo.Size())
+	}
+}
+
+func TestLogReader_PageUp_continuesIntoRotatedFile(t *testing.T) {
+	expected := [][]string{
+		{"03/11/2010", "Thread-3", "com.test"},
+		{"04/11/2010", "Thread-4", "com.test"},
+		{"05/11/2010", "Thread-5", "com.test"},
+	}
+
+	logReader := NewLogReader(rotatedReaderConfig())
+	logReader.SetCapacity(3)
+	logReader.Tail()
+	result := *logReader.PageUp()
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+}
+
+func TestLogReader_Head_startsAtOldestRotatedFile(t *testing.T) {
+	expected := [][]string{
+		{"01/11/2010", "Thread-1", "com.test"},
+		{"02/11/2010", "Thread-2", "com.test"},
+		{"03/11/2010", "Thread-3", "com.test"},
+	}
+
+	logReader := NewLogReader(rotatedReaderConfig())
+	logReader.SetCapacity(3)
+	result := *logReader.Head()
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+	if progress := logReader.Progress(); progress != 87*100/232 {
+		t.Errorf("Expected the progress to span the whole family, got %d", progress)
+	}
+}
@@ -0,0 +1,347 @@
+package logreader
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//Decides which log lines match a search term
+type Matcher interface {
+	//Returns true if the line matches
+	Match(line string) bool
+	//Returns the start and end index of every match in the text, used to highlight the matches
+	FindAll(text string) [][]int
+}
+
+//How a search term is matched against the log lines
+//By default the term is matched as a case insensitive substring, with Query the term is a query over the columns, see Query
+type SearchOptions struct {
+	Regex         bool
+	CaseSensitive bool
+	WholeWord     bool
+	Query         bool
+}
+
+//Implemented by matchers that match the columns of the lines rather than their text
+type columnMatcher interface {
+	matchColumns(line string, columns []string) bool
+}
+
+//A matcher of the columns of the lines, such as the severity rules of the display
+//The reader matches the lines of each file through MatchColumns with the headers of the file
+type ColumnMatcher interface {
+	Matcher
+	//Returns true if the line, split into the columns named by the headers, matches
+	MatchColumns(headers []string, line string, columns []string) bool
+}
+
+//A column matcher bound to the headers of a file, see LogReader.bind
+type boundColumns struct {
+	ColumnMatcher
+	headers []string
+}
+
+func (m boundColumns) matchColumns(line string, columns []string) bool {
+	return m.MatchColumns(m.headers, line, columns)
+}
+
+//Matches only the lines starting a log entry, see EntriesOnly
+type entriesMatcher struct {
+	Matcher
+}
+
+//The offsets of the lines of a view matching a matcher, kept so the matches around the page are counted without reading the whole file every time
+//The offsets are filled in the background and extended as the file grows, the file is read again if it was replaced or truncated
+type matchOffsets struct {
+	mutex    sync.Mutex
+	matcher  Matcher
+	path     string
+	identity uint64
+	scanned  int
+	//Where the last entry read starts
+	last    int
+	offsets []int
+	//Whether the whole file was read once, and whether an update is running
+	filled   bool
+	updating bool
+	running  sync.WaitGroup
+}
+
+//Number of matchers whose offsets are kept for each view, such as the search and the severity levels
+const matchCacheSize = 4
+
+//The match offsets of a view for the matchers last counted in it, the least recently used are dropped
+type matchCache struct {
+	mutex   sync.Mutex
+	offsets []*matchOffsets
+}
+
+//Matches lines against a regular expression, plain search terms are quoted into one
+type regexMatcher struct {
+	pattern *regexp.Regexp
+}
+
+//Compiles the search term into a matcher using the options
+//Returns an error if the term is not a valid regular expression or query
+func NewMatcher(term string, options SearchOptions) (Matcher, error) {
+	if options.Query {
+		return ParseQuery(term, options)
+	}
+	expression := term
+	if !options.Regex {
+		expression = regexp.QuoteMeta(term)
+	}
+	if options.WholeWord {
+		expression = `\b(?:` + expression + `)\b`
+	}
+	if !options.CaseSensitive {
+		expression = `(?i)` + expression
+	}
+
+	pattern, err := regexp.Compile(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex: %v", strings.TrimPrefix(err.Error(), "error parsing regexp: "))
+	}
+	return regexMatcher{pattern}, nil
+}
+
+func (m regexMatcher) Match(line string) bool {
+	return m.pattern.MatchString(line)
+}
+
+func (m regexMatcher) FindAll(text string) [][]int {
+	return m.pattern.FindAllStringIndex(text, -1)
+}
+
+//Wraps the matcher so it only matches the lines starting a log entry, never their continuation lines
+func EntriesOnly(matcher Matcher) Matcher {
+	return entriesMatcher{matcher}
+}
+
+//Returns a short description of the options, such as "regex, case sensitive", empty for the default options
+func (o SearchOptions) String() string {
+	var modes []string
+	if o.Regex {
+		modes = append(modes, "regex")
+	}
+	if o.CaseSensitive {
+		modes = append(modes, "case sensitive")
+	}
+	if o.WholeWord {
+		modes = append(modes, "whole word")
+	}
+	if o.Query {
+		modes = append(modes, "query")
+	}
+	return strings.Join(modes, ", ")
+}
+
+//Returns true if the line of the file read by the parser matches, queries are matched against the columns of the line
+func matchLine(matcher Matcher, parser lineParser, line string) bool {
+	if entries, ok := matcher.(entriesMatcher); ok {
+		return parser.isEntry(line) && matchLine(entries.Matcher, parser, line)
+	}
+	if columns, ok := matcher.(columnMatcher); ok {
+		return columns.matchColumns(logText(parser, line), parser.parse(line))
+	}
+	return matcher.Match(logText(parser, line))
+}
+
+//Finds the first line starting at or after the offset that matches
+//Returns the offset of the start of the line or -1 if no line matches
+func searchFile(file logSource, parser lineParser, matcher Matcher, offset int) int {
+	if _, err := file.Seek(int64(offset), io.SeekStart); err != nil {
+		return -1
+	}
+
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 && matchLine(matcher, parser, strings.TrimRight(line, "\r\n")) {
+			return offset
+		}
+		offset += len(line)
+		if err != nil {
+			return -1
+		}
+	}
+}
+
+//Finds the last line starting before the offset that matches, the offset must be the start of a line
+//The lines are read back from the checkpoints of the line index of the file where it reaches, see backwardLines
+//Returns the offset of the start of the line or -1 if no line matches
+func searchFileBackward(file logSource, parser lineParser, index *lineIndex, matcher Matcher, offset int) int {
+	lines := newBackwardLines(file, offset, index)
+	for {
+		line, start, ok := lines.previous()
+		if !ok {
+			return -1
+		}
+		if matchLine(matcher, parser, line) {
+			return start
+		}
+	}
+}
+
+//Returns true if both are the same matcher, matchers that can't be compared are never the same
+func sameMatcher(a, b Matcher) bool {
+	if entries, ok := a.(entriesMatcher); ok {
+		other, ok := b.(entriesMatcher)
+		return ok && sameMatcher(entries.Matcher, other.Matcher)
+	}
+	if a == nil || b == nil || reflect.TypeOf(a) != reflect.TypeOf(b) || !reflect.TypeOf(a).Comparable() {
+		return false
+	}
+	return a == b
+}
+
+//Starts reading the lines added to the file at the path since the last update in the background, see update
+//progress is called once the offsets changed, nothing is started while an update is running
+func (m *matchOffsets) refresh(path string, open func() (logSource, error), parser lineParser, bound Matcher, progress func()) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.updating {
+		return
+	}
+	m.updating = true
+	m.running.Add(1)
+	go func() {
+		defer m.running.Done()
+		changed := m.update(path, open, parser, bound)
+
+		m.mutex.Lock()
+		m.updating = false
+		m.mutex.Unlock()
+		if changed {
+			progress()
+		}
+	}()
+}
+
+//Waits for the update running in the background to finish
+func (m *matchOffsets) wait() {
+	m.running.Wait()
+}
+
+//Reads the lines added to the file at the path since the last update and keeps the offsets of those matching
+//The lines are matched with the matcher bound to the headers of the file, see LogReader.bind, the offsets are kept for the unbound one
+//Lines still being written are left for the next update, the offsets are counted while the file is read without the lock
+//Returns true if the offsets changed
+func (m *matchOffsets) update(path string, open func() (logSource, error), parser lineParser, bound Matcher) bool {
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	file, err := open()
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	sourceInfo, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	m.mutex.Lock()
+	changed := !m.filled
+	if m.path != path || fileIdentity(fileInfo) != m.identity || sourceInfo.Size() < int64(m.scanned) {
+		m.path, m.scanned, m.last, m.offsets, m.filled = path, 0, 0, nil, false
+		changed = true
+	}
+	m.identity = fileIdentity(fileInfo)
+	//The last entry is matched again, the filtered copy rewrites its last entry as continuation lines are added
+	if sourceInfo.Size() > int64(m.scanned) && m.scanned > m.last {
+		m.offsets = m.offsets[:sort.SearchInts(m.offsets, m.last)]
+		m.scanned = m.last
+	}
+	scanned, last := m.scanned, m.last
+	m.mutex.Unlock()
+
+	var offsets []int
+	if _, err := file.Seek(int64(scanned), io.SeekStart); err == nil {
+		reader := bufio.NewReader(io.LimitReader(file, sourceInfo.Size()-int64(scanned)))
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil || !strings.HasSuffix(line, "\n") {
+				break
+			}
+			text := strings.TrimRight(line, "\r\n")
+			if matchLine(bound, parser, text) {
+				offsets = append(offsets, scanned)
+			}
+			if parser.isEntry(text) {
+				last = scanned
+			}
+			scanned += len(line)
+		}
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	changed = changed || scanned != m.scanned
+	m.offsets = append(m.offsets, offsets...)
+	m.scanned, m.last, m.filled = scanned, last, true
+	return changed
+}
+
+//Returns the number of matching lines starting before the start offset and at or after the end offset
+//Returns false until the whole file was read once
+func (m *matchOffsets) around(start int, end int) (int, int, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return sort.SearchInts(m.offsets, start), len(m.offsets) - sort.SearchInts(m.offsets, end), m.filled
+}
+
+//Returns the number of matching lines starting before the offset and the total number of matching lines
+//Returns false until the whole file was read once
+func (m *matchOffsets) position(offset int) (int, int, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return sort.SearchInts(m.offsets, offset), len(m.offsets), m.filled
+}
+
+//Returns the offsets of the matcher, new offsets are kept in place of the least recently used ones
+func (c *matchCache) get(matcher Matcher) *matchOffsets {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	found := -1
+	for index, offsets := range c.offsets {
+		offsets.mutex.Lock()
+		same := sameMatcher(offsets.matcher, matcher)
+		offsets.mutex.Unlock()
+		if same {
+			found = index
+			break
+		}
+	}
+	var offsets *matchOffsets
+	if found == -1 {
+		offsets = &matchOffsets{matcher: matcher}
+		if len(c.offsets) == matchCacheSize {
+			c.offsets = c.offsets[:matchCacheSize-1]
+		}
+	} else {
+		offsets = c.offsets[found]
+		c.offsets = append(c.offsets[:found], c.offsets[found+1:]...)
+	}
+	c.offsets = append([]*matchOffsets{offsets}, c.offsets...)
+	return offsets
+}
+
+//Forgets the offsets of every matcher
+func (c *matchCache) reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.offsets = nil
+}
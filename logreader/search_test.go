@@ -0,0 +1,204 @@
+package logreader
+
+import (
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+//Counts the matches above and below the page once the lines added since the count running, if any, are counted
+func matchesAround(logReader *LogReader, matcher Matcher) (int, int) {
+	logReader.matches[logReader.FileIndex].get(matcher).wait()
+	logReader.MatchesAround(matcher)
+	logReader.matches[logReader.FileIndex].get(matcher).wait()
+	above, below, _ := logReader.MatchesAround(matcher)
+	return above, below
+}
+
+//Returns the position of the match at the location once the matches are counted, see matchesAround
+func matchPosition(logReader *LogReader, matcher Matcher, location int) (int, int) {
+	logReader.matches[logReader.FileIndex].get(matcher).wait()
+	logReader.MatchPosition(matcher, location)
+	logReader.matches[logReader.FileIndex].get(matcher).wait()
+	current, total, _ := logReader.MatchPosition(matcher, location)
+	return current, total
+}
+
+func TestSearch_NewMatcher_modes(t *testing.T) {
+	cases := []struct {
+		term    string
+		options SearchOptions
+		line    string
+		match   bool
+	}{
+		{"error", SearchOptions{}, "An ERROR occurred", true},
+		{"error", SearchOptions{CaseSensitive: true}, "An ERROR occurred", false},
+		{"(Observable.java:7204)", SearchOptions{}, "at rx.Observable$31.onError(Observable.java:7204)", true},
+		{`Thread-\d+`, SearchOptions{Regex: true}, "11/11/2010~Thread-12~com.test", true},
+		{`Thread-\d+`, SearchOptions{}, "11/11/2010~Thread-12~com.test", false},
+		{"test", SearchOptions{WholeWord: true}, "11/11/2010~Thread-1~com.test", true},
+		{"tes", SearchOptions{WholeWord: true}, "11/11/2010~Thread-1~com.test", false},
+	}
+	for _, c := range cases {
+		matcher, err := NewMatcher(c.term, c.options)
+		if err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+		if actual := matcher.Match(c.line); actual != c.match {
+			t.Errorf("Expected %q with %+v on %q to be %v", c.term, c.options, c.line, c.match)
+		}
+	}
+}
+
+func TestSearch_NewMatcher_invalidRegex(t *testing.T) {
+	if _, err := NewMatcher("Thread-(", SearchOptions{Regex: true}); err == nil {
+		t.Errorf("Expected an error for an invalid regular expression")
+	}
+	if _, err := NewMatcher("Thread-(", SearchOptions{}); err != nil {
+		t.Errorf("Expected plain text to be quoted, got %v", err)
+	}
+}
+
+func TestLogReader_SearchWith_regex(t *testing.T) {
+	input := "../test_logs/TestLogReader_Search.log"
+	expected := [][]string{{"Caused by: rx.exceptions.MissingBackpressureException"}, {"       at rx.internal.util.RxRingBuffer.onNext(RxRingBuffer.java:222)"}}
+
+	logReader := NewLogReader(logreaderConfig(input, []int{10, 10, 10}))
+	logReader.SetCapacity(2)
+	matcher, _ := NewMatcher(`Missing\w+Exception$`, SearchOptions{Regex: true, CaseSensitive: true})
+	actual, location := logReader.SearchWith(matcher, -1)
+
+	if !reflect.DeepEqual(*actual, expected) || location != 0 {
+		t.Errorf("Expected %s lines at 0, got %s at %d", expected, *actual, location)
+	}
+}
+
+func TestLogReader_SearchBackward_wrapsToEnd(t *testing.T) {
+	input := "../test_logs/TestLogReader_Search.log"
+	logReader := NewLogReader(logreaderConfig(input, []int{10, 10, 10}))
+	logReader.SetCapacity(2)
+	matcher, _ := NewMatcher("Caused by:", SearchOptions{})
+
+	expected := [][]string{{"Caused by: rx.exceptions.MissingBackpressureException"}, {"       at rx.internal.util.RxRingBuffer.onNext(RxRingBuffer.java:222)"}}
+	actual, location := logReader.SearchBackward(matcher, -1)
+	if !reflect.DeepEqual(*actual, expected) || location != 0 {
+		t.Errorf("Expected %s lines at 0, got %s at %d", expected, *actual, location)
+	}
+	if current, total := matchPosition(&logReader, matcher, location); current != 2 || total != 2 {
+		t.Errorf("Expected match 2 of 2, got %d of %d", current, total)
+	}
+
+	expected = [][]string{{"Caused by: rx.exceptions.OnErrorNotImplementedException"}, {"       at rx.Observable$31.onError(Observable.java:7204)"}}
+	actual, location = logReader.SearchBackward(matcher, location)
+	if !reflect.DeepEqual(*actual, expected) || location != 0 {
+		t.Errorf("Expected %s lines at 0, got %s at %d", expected, *actual, location)
+	}
+	if current, total := matchPosition(&logReader, matcher, location); current != 1 || total != 2 {
+		t.Errorf("Expected match 1 of 2, got %d of %d", current, total)
+	}
+}
+
+func TestSearch_searchFileBackward_acrossBlocks(t *testing.T) {
+	content := numberedLines(0, 20000)
+	path, cleanup := tempLog(t, content)
+	defer cleanup()
+	file, _ := os.Open(path)
+	defer file.Close()
+
+	matcher, _ := NewMatcher(`^line (5|19999)$`, SearchOptions{Regex: true})
+	if offset := searchFileBackward(file, separatorParser{}, nil, matcher, len(content)); offset != strings.Index(content, "line 19999\n") {
+		t.Errorf("Expected the last line to match, got offset %d", offset)
+	}
+	if offset := searchFileBackward(file, separatorParser{}, nil, matcher, strings.Index(content, "line 19999\n")); offset != strings.Index(content, "line 5\n") {
+		t.Errorf("Expected line 5 to match, got offset %d", offset)
+	}
+	if offset := searchFileBackward(file, separatorParser{}, nil, matcher, strings.Index(content, "line 5\n")); offset != -1 {
+		t.Errorf("Expected no match before line 5, got offset %d", offset)
+	}
+}
+
+func TestLogReader_MatchesAround(t *testing.T) {
+	path, cleanup := tempLog(t, "1~Thread-1~ERROR\n2~Thread-2~INFO\n3~Thread-3~ERROR\n4~Thread-4~ERROR\n5~Thread-5~INFO\n6~Thread-6~ERROR\n")
+	defer cleanup()
+
+	logReader := NewLogReader(logreaderConfig(path, []int{10, 10, 10}))
+	defer logReader.Close()
+	logReader.SetCapacity(2)
+	matcher := EntriesOnly(regexMatcher{regexp.MustCompile("ERROR")})
+	logReader.SeekLine(3)
+	if above, below := matchesAround(&logReader, matcher); above != 1 || below != 1 {
+		t.Errorf("Expected 1 match above and 1 below lines 3-4, got %d and %d", above, below)
+	}
+
+	file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	file.WriteString("7~Thread-7~ERROR\n    at ERROR in a stack trace\n")
+	file.Close()
+	if above, below := matchesAround(&logReader, matcher); above != 1 || below != 2 {
+		t.Errorf("Expected the appended entry to be counted without its continuation line, got %d and %d", above, below)
+	}
+}
+
+func TestLogReader_MatchPosition_keepsTheOffsetsOfEachMatcher(t *testing.T) {
+	path, cleanup := tempLog(t, "1~Thread-1~ERROR\n2~Thread-2~INFO\n3~Thread-3~ERROR\n")
+	defer cleanup()
+
+	logReader := NewLogReader(logreaderConfig(path, []int{10, 10, 10}))
+	defer logReader.Close()
+	logReader.SetCapacity(1)
+	errors, _ := NewMatcher("ERROR", SearchOptions{})
+	infos, _ := NewMatcher("INFO", SearchOptions{})
+	logReader.Tail()
+	if current, total := matchPosition(&logReader, errors, 0); current != 2 || total != 2 {
+		t.Errorf("Expected match 2 of 2, got %d of %d", current, total)
+	}
+	if above, below := matchesAround(&logReader, infos); above != 1 || below != 0 {
+		t.Errorf("Expected 1 match above, got %d above and %d below", above, below)
+	}
+
+	file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	file.WriteString("4~Thread-4~ERROR\n")
+	file.Close()
+	if current, total := matchPosition(&logReader, errors, 0); current != 2 || total != 3 {
+		t.Errorf("Expected match 2 of 3 once a match was appended, got %d of %d", current, total)
+	}
+	if cached := len(logReader.matches[0].offsets); cached != 2 {
+		t.Errorf("Expected the offsets of both matchers to be kept, got %d", cached)
+	}
+}
+
+func TestSearch_sameMatcher(t *testing.T) {
+	matcher, _ := NewMatcher("ERROR", SearchOptions{})
+	other, _ := NewMatcher("ERROR", SearchOptions{})
+	query, _ := ParseQuery("Severity=ERROR", SearchOptions{})
+	if !sameMatcher(matcher, matcher) || !sameMatcher(EntriesOnly(query), EntriesOnly(query)) {
+		t.Errorf("Expected a matcher to be the same as itself")
+	}
+	if sameMatcher(matcher, other) || sameMatcher(matcher, EntriesOnly(matcher)) || sameMatcher(nil, matcher) {
+		t.Errorf("Expected different matchers not to be the same")
+	}
+}
+
+func TestLogReader_MatchesAround_countsInTheBackground(t *testing.T) {
+	path, cleanup := tempLog(t, "1~Thread-1~ERROR\n2~Thread-2~INFO\n3~Thread-3~ERROR\n")
+	defer cleanup()
+
+	logReader := NewLogReader(logreaderConfig(path, []int{10, 10, 10}))
+	defer logReader.Close()
+	logReader.SetCapacity(1)
+	matcher, _ := NewMatcher("ERROR", SearchOptions{})
+	logReader.Head()
+	if _, _, ready := logReader.MatchesAround(matcher); ready {
+		t.Errorf("Expected the first count not to be ready before it was read")
+	}
+	select {
+	case <-logReader.Updates():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected the count to be reported once it's ready")
+	}
+	if above, below, ready := logReader.MatchesAround(matcher); !ready || above != 0 || below != 1 {
+		t.Errorf("Expected 0 matches above and 1 below once ready, got %d, %d and %v", above, below, ready)
+	}
+}
@@ -0,0 +1,59 @@
+package logreader
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+//File name that reads the log from the standard input, for example kubectl logs -f pod | golog
+const StdinPath = "-"
+
+//Copies a stream that can't be seeked (the standard input or a named pipe) into a temp file as the data arrives
+//The temp file is then paged, searched and tailed like a regular log file
+type streamSpool struct {
+	path string
+}
+
+//Returns true if the log file is read from a stream rather than a regular file
+func isStream(path string) bool {
+	if path == StdinPath {
+		return true
+	}
+	fileInfo, err := os.Stat(path)
+	return err == nil && fileInfo.Mode()&os.ModeNamedPipe != 0
+}
+
+//Creates the temp file and starts copying the stream into it in the background until the stream ends
+//Named pipes are opened in the background as well since opening them blocks until a writer connects
+//Returns an error if the temp file cannot be created
+func startSpool(path string) (*streamSpool, error) {
+	output, err := ioutil.TempFile("", "golog-stream-*.log")
+	if err != nil {
+		return nil, err
+	}
+
+	spool := &streamSpool{path: output.Name()}
+	go func() {
+		defer output.Close()
+
+		var input io.ReadCloser = os.Stdin
+		if path != StdinPath {
+			pipe, err := os.Open(path)
+			if err != nil {
+				return
+			}
+			defer pipe.Close()
+			input = pipe
+		}
+
+		io.Copy(output, input)
+	}()
+
+	return spool, nil
+}
+
+//Removes the temp file
+func (s *streamSpool) remove() {
+	os.Remove(s.path)
+}
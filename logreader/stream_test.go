@@ -0,0 +1,68 @@
+//go:build !windows
+
+package logreader
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestStream_isStream(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	pipe := filepath.Join(dir, "app.pipe")
+	syscall.Mkfifo(pipe, 0644)
+
+	if !isStream(StdinPath) || !isStream(pipe) {
+		t.Errorf("Expected the standard input and named pipes to be read as streams")
+	}
+	if isStream("../test_logs/TestLogReader_Tail_input.log") {
+		t.Errorf("Expected a regular file not to be read as a stream")
+	}
+}
+
+func TestLogReader_namedPipe(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	pipe := filepath.Join(dir, "app.pipe")
+	if err := syscall.Mkfifo(pipe, 0644); err != nil {
+		t.Skipf("Named pipes are not supported: %v", err)
+	}
+
+	logReader := NewLogReader(logreaderConfig(pipe, []int{10, 10, 10}))
+	defer logReader.Close()
+	logReader.SetCapacity(2)
+
+	writer, err := os.OpenFile(pipe, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("Could not open the pipe for writing: %v", err)
+	}
+	writer.WriteString("11/11/2010~Thread-1~com.test\n12/11/2010~Thread-2~com.test\n13/11/2010~Thread-3~com.test\n")
+	writer.Close()
+
+	expectedTail := [][]string{{"12/11/2010", "Thread-2", "com.test"}, {"13/11/2010", "Thread-3", "com.test"}}
+	deadline := time.Now().Add(5 * time.Second)
+	result := *logReader.Tail()
+	for !reflect.DeepEqual(result, expectedTail) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		result = *logReader.Tail()
+	}
+	if !reflect.DeepEqual(result, expectedTail) {
+		t.Fatalf(`Output Log: Expected %s got %s`, expectedTail, result)
+	}
+
+	expectedHead := [][]string{{"11/11/2010", "Thread-1", "com.test"}, {"12/11/2010", "Thread-2", "com.test"}}
+	if result := *logReader.Head(); !reflect.DeepEqual(result, expectedHead) {
+		t.Errorf(`Output Log: Expected %s got %s`, expectedHead, result)
+	}
+
+	spool := logReader.FilePaths()[0]
+	logReader.Close()
+	if _, err := os.Stat(spool); !os.IsNotExist(err) {
+		t.Errorf("Expected the spool %s to be removed on close", spool)
+	}
+}
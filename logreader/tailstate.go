@@ -0,0 +1,70 @@
+package logreader
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+//Number of bytes at the start of a file used to recognize it was rewritten in place
+const fingerprintSize = 64
+
+//The state of a file as of its last tail, used to detect changes, truncation and rotation
+type tailState struct {
+	fileInfo    os.FileInfo
+	fingerprint []byte
+	page        *[][]string
+	//The offsets of the first line of the page and right after it
+	start       int
+	offset      int
+	notice      string
+}
+
+//Reads the first bytes of the file
+//Returns an empty fingerprint if the file cannot be read
+func readFingerprint(path string) []byte {
+	file, err := os.Open(path)
+	if err != nil {
+		return []byte{}
+	}
+	defer file.Close()
+
+	fingerprint := make([]byte, fingerprintSize)
+	n, _ := io.ReadFull(file, fingerprint)
+	return fingerprint[:n]
+}
+
+//Compares the file with its state as of the last tail
+//Returns a notice describing a rotation (the path points to a new file) or a truncation (the file shrank or was rewritten), or an empty string
+func (s tailState) detectRotation(fileInfo os.FileInfo, fingerprint []byte) string {
+	if s.fileInfo == nil {
+		return ""
+	}
+
+	if !os.SameFile(s.fileInfo, fileInfo) {
+		return "file rotated"
+	}
+
+	//copytruncate empties the file and the application keeps writing to it, the start of the file changes even if it grew back
+	if fileInfo.Size() < s.fileInfo.Size() || !sameStart(s.fingerprint, fingerprint) {
+		return "file truncated"
+	}
+
+	return ""
+}
+
+//Returns true if both fingerprints are the same as far as the shorter one goes, a file only appended to keeps its first bytes
+func sameStart(previous []byte, current []byte) bool {
+	sharedLength := len(previous)
+	if len(current) < sharedLength {
+		sharedLength = len(current)
+	}
+	return bytes.Equal(previous[:sharedLength], current[:sharedLength])
+}
+
+//Returns true if the file didn't change since the last tail
+func (s tailState) unchanged(fileInfo os.FileInfo) bool {
+	return s.fileInfo != nil && s.page != nil &&
+		fileInfo.Size() == s.fileInfo.Size() &&
+		fileInfo.ModTime().Equal(s.fileInfo.ModTime())
+}
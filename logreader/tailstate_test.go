@@ -0,0 +1,97 @@
+package logreader
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeTestLog(t *testing.T, path string, content string) {
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Could not write %s: %v", path, err)
+	}
+}
+
+//Creates a temporary directory, returns it with the function removing it
+func tempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "golog")
+	if err != nil {
+		t.Fatalf("Could not create a temporary directory: %v", err)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+//Writes the content to app.log in a temporary directory, returns its path with the function removing the directory
+func tempLog(t *testing.T, content string) (string, func()) {
+	dir, cleanup := tempDir(t)
+	path := filepath.Join(dir, "app.log")
+	writeTestLog(t, path, content)
+	return path, cleanup
+}
+
+func TestLogReader_Tail_detectsTruncation(t *testing.T) {
+	path, cleanup := tempLog(t, "11/11/2010~Thread-1~com.test\n12/11/2010~Thread-2~com.test\n13/11/2010~Thread-3~com.test\n")
+	defer cleanup()
+
+	logReader := NewLogReader(logreaderConfig(path, []int{10, 10, 10}))
+	logReader.SetCapacity(2)
+	logReader.Tail()
+	if notice := logReader.RotationNotice(); notice != "" {
+		t.Errorf("Expected no notice on the first tail, got %s", notice)
+	}
+
+	//copytruncate: the file is emptied in place and the application keeps writing
+	writeTestLog(t, path, "20/11/2010~Thread-9~com.test\n21/11/2010~Thread-10~com.test\n")
+	expected := [][]string{{"20/11/2010", "Thread-9", "com.test"}, {"21/11/2010", "Thread-10", "com.test"}}
+	if result := *logReader.Tail(); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+	if notice := logReader.RotationNotice(); notice != "file truncated" {
+		t.Errorf("Expected the truncation to be detected, got %q", notice)
+	}
+	if notice := logReader.RotationNotice(); notice != "" {
+		t.Errorf("Expected the notice to be cleared once read, got %q", notice)
+	}
+	if progress := logReader.Progress(); progress != 100 {
+		t.Errorf("Expected the offset to be rewound to the end of the truncated file, got progress %d", progress)
+	}
+}
+
+func TestLogReader_Tail_detectsRotation(t *testing.T) {
+	path, cleanup := tempLog(t, "11/11/2010~Thread-1~com.test\n12/11/2010~Thread-2~com.test\n")
+	defer cleanup()
+
+	logReader := NewLogReader(logreaderConfig(path, []int{10, 10, 10}))
+	logReader.SetCapacity(2)
+	logReader.Tail()
+
+	os.Rename(path, path+".1")
+	writeTestLog(t, path, "11/11/2010~Thread-1~com.test\n12/11/2010~Thread-2~com.test\n13/11/2010~Thread-3~com.test\n")
+	expected := [][]string{
+		{"12/11/2010", "Thread-2", "com.test"},
+		{"13/11/2010", "Thread-3", "com.test"},
+	}
+	if result := *logReader.Tail(); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+	if notice := logReader.RotationNotice(); notice != "file rotated" {
+		t.Errorf("Expected the rotation to be detected, got %q", notice)
+	}
+}
+
+func TestLogReader_PageUp_afterTruncationDoesNotReadPastEnd(t *testing.T) {
+	path, cleanup := tempLog(t, "11/11/2010~Thread-1~com.test\n12/11/2010~Thread-2~com.test\n13/11/2010~Thread-3~com.test\n14/11/2010~Thread-4~com.test\n")
+	defer cleanup()
+
+	logReader := NewLogReader(logreaderConfig(path, []int{10, 10, 10}))
+	logReader.SetCapacity(1)
+	logReader.Tail()
+	writeTestLog(t, path, "20/11/2010~Thread-9~com.test\n21/11/2010~Thread-10~com.test\n")
+
+	expected := [][]string{{"20/11/2010", "Thread-9", "com.test"}}
+	if result := *logReader.PageUp(); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+}
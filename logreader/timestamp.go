@@ -0,0 +1,245 @@
+package logreader
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+//Layouts tried in order when the timestamp layout isn't configured, day first dates (11/11/2010) are assumed before month first ones
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.000",
+	"2006-01-02 15:04:05,000",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	"02/01/2006 15:04:05.000",
+	"02/01/2006 15:04:05",
+	"02/01/2006",
+	"01/02/2006 15:04:05",
+	"01/02/2006",
+	"02/Jan/2006:15:04:05 -0700",
+	"Jan _2 15:04:05",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.ANSIC,
+}
+
+//Number of lines at the start of a file searched for a timestamp to detect its layout
+const layoutDetectionLines = 100
+
+//Layouts of a time of day typed without a date, the date is taken from the log
+var timeOfDayLayouts = []string{
+	"15:04:05.000",
+	"15:04:05",
+	"15:04",
+}
+
+//The Go layout for each strftime directive
+var strftimeDirectives = map[byte]string{
+	'Y': "2006", 'y': "06", 'm': "01", 'd': "02", 'e': "_2", 'b': "Jan", 'h': "Jan", 'B': "January",
+	'a': "Mon", 'A': "Monday", 'H': "15", 'I': "03", 'M': "04", 'S': "05", 'p': "PM", 'f': "000000",
+	'z': "-0700", 'Z': "MST", 'F': "2006-01-02", 'T': "15:04:05", 'D': "01/02/06", '%': "%",
+}
+
+//Reads the time of a log entry from the configured timestamp column
+type timestampParser struct {
+	column int
+	//Go layout of the timestamps, empty to try every known layout, see detectLayout
+	layout string
+}
+
+//Creates the timestamp parser described by the configuration, returns nil if no timestamp column is configured
+//Returns an error if the column isn't one of the headers or the strftime layout has an unknown directive
+func newTimestampParser(config LogReaderConfig) (*timestampParser, error) {
+	if config.TimestampColumn == "" {
+		return nil, nil
+	}
+
+	column := -1
+	for index, header := range config.Headers {
+		if header.Header == config.TimestampColumn {
+			column = index
+		}
+	}
+	if column == -1 {
+		return nil, fmt.Errorf("timestamp column %q is not one of the headers", config.TimestampColumn)
+	}
+
+	layout, err := goLayout(config.TimestampLayout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &timestampParser{column, layout}, nil
+}
+
+//Converts a strftime layout (%Y-%m-%d) to a Go layout, Go layouts are returned as they are
+//"auto" and an empty layout detect the layout from the timestamps
+func goLayout(layout string) (string, error) {
+	if layout == "auto" || !strings.Contains(layout, "%") {
+		if layout == "auto" {
+			return "", nil
+		}
+		return layout, nil
+	}
+
+	var converted strings.Builder
+	for i := 0; i < len(layout); i++ {
+		if layout[i] != '%' {
+			converted.WriteByte(layout[i])
+			continue
+		}
+		if i+1 == len(layout) {
+			return "", fmt.Errorf("timestamp layout %q ends with %%", layout)
+		}
+		i++
+		directive, found := strftimeDirectives[layout[i]]
+		if !found {
+			return "", fmt.Errorf("timestamp layout %q has an unknown directive %%%c", layout, layout[i])
+		}
+		converted.WriteString(directive)
+	}
+
+	return converted.String(), nil
+}
+
+//Returns the time of a parsed row, false if the row has no timestamp in the layout
+//Without a layout the known layouts are tried in order, the parser is left as it is so it can be shared
+func (p timestampParser) parse(row []string) (time.Time, bool) {
+	if p.column >= len(row) {
+		return time.Time{}, false
+	}
+	text := strings.TrimSpace(row[p.column])
+	if text == "" {
+		return time.Time{}, false
+	}
+
+	if p.layout != "" {
+		timestamp, err := time.Parse(p.layout, text)
+		return timestamp, err == nil
+	}
+
+	for _, layout := range timestampLayouts {
+		if timestamp, err := time.Parse(layout, text); err == nil {
+			return timestamp, true
+		}
+	}
+	return time.Time{}, false
+}
+
+//Returns the first of the known layouts the timestamp of a parsed row is in, empty if the row has no timestamp
+func (p timestampParser) layoutOf(row []string) string {
+	if p.column >= len(row) {
+		return ""
+	}
+	text := strings.TrimSpace(row[p.column])
+	if text == "" {
+		return ""
+	}
+	for _, layout := range timestampLayouts {
+		if _, err := time.Parse(layout, text); err == nil {
+			return layout
+		}
+	}
+	return ""
+}
+
+//Returns the layout of the first timestamp among the first lines of the file, empty if none of them has one
+func detectLayout(file logSource, parser lineParser, timestamps timestampParser, lines int) string {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return ""
+	}
+
+	reader := bufio.NewReader(file)
+	for ; lines > 0; lines-- {
+		line, err := reader.ReadString('\n')
+		if text := strings.TrimRight(line, "\r\n"); parser.isEntry(text) {
+			if layout := timestamps.layoutOf(parser.parse(text)); layout != "" {
+				return layout
+			}
+		}
+		if err != nil {
+			return ""
+		}
+	}
+	return ""
+}
+
+//Parses a time typed by the user, either in the layout of the log, in one of the common layouts or as a time of day
+//A time of day is taken to be on the date of the reference time
+func (p timestampParser) parseInput(input string, reference time.Time) (time.Time, error) {
+	input = strings.TrimSpace(input)
+	layouts := timestampLayouts
+	if p.layout != "" {
+		layouts = append([]string{p.layout}, timestampLayouts...)
+	}
+	for _, layout := range layouts {
+		if timestamp, err := time.Parse(layout, input); err == nil {
+			return timestamp, nil
+		}
+	}
+
+	for _, layout := range timeOfDayLayouts {
+		if timeOfDay, err := time.Parse(layout, input); err == nil {
+			year, month, day := reference.Date()
+			return time.Date(year, month, day, timeOfDay.Hour(), timeOfDay.Minute(), timeOfDay.Second(), timeOfDay.Nanosecond(), reference.Location()), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%q is not a time", input)
+}
+
+//Finds the first log entry with a timestamp starting at or after the offset, the rest of the line the offset falls in is skipped
+//Returns the offset of the entry and its time, false if there is no such entry before the end of the file
+func nextTimestamp(file logSource, parser lineParser, timestamps *timestampParser, offset int) (int, time.Time, bool) {
+	if offset > 0 {
+		_, offset, _ = head(file, 1, offset-1)
+	}
+	if _, err := file.Seek(int64(offset), io.SeekStart); err != nil {
+		return offset, time.Time{}, false
+	}
+
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			text := strings.TrimRight(line, "\r\n")
+			if parser.isEntry(text) {
+				if timestamp, ok := timestamps.parse(parser.parse(text)); ok {
+					return offset, timestamp, true
+				}
+			}
+			offset += len(line)
+		}
+		if err != nil {
+			return offset, time.Time{}, false
+		}
+	}
+}
+
+//Binary searches the file by byte offset for the first entry at or after the time, assuming the entries are in time order
+//Returns the offset of the entry, or the end of the file and false if all entries are before the time
+func searchTime(file logSource, parser lineParser, timestamps *timestampParser, target time.Time) (int, bool) {
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return 0, false
+	}
+
+	low, high := 0, int(fileInfo.Size())
+	for low < high {
+		middle := low + (high-low)/2
+		offset, timestamp, found := nextTimestamp(file, parser, timestamps, middle)
+		if found && timestamp.Before(target) {
+			low = offset + 1
+		} else {
+			high = middle
+		}
+	}
+
+	offset, _, found := nextTimestamp(file, parser, timestamps, low)
+	return offset, found
+}
@@ -0,0 +1,115 @@
+package logreader
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func timestampConfig() LogReaderConfig {
+	config := logreaderConfig("../test_logs/TestLogReader_Tail_input.log", []int{10, 10, 10})
+	config.TimestampColumn = "Date"
+	return config
+}
+
+func TestTimestamp_goLayout(t *testing.T) {
+	layouts := map[string]string{
+		"%Y-%m-%d %H:%M:%S":      "2006-01-02 15:04:05",
+		"%d/%b/%Y:%T %z":         "02/Jan/2006:15:04:05 -0700",
+		"2006-01-02T15:04:05Z07": "2006-01-02T15:04:05Z07",
+		"auto":                   "",
+	}
+	for layout, expected := range layouts {
+		if actual, err := goLayout(layout); err != nil || actual != expected {
+			t.Errorf("Expected %q to be converted to %q, got %q (%v)", layout, expected, actual, err)
+		}
+	}
+
+	if _, err := goLayout("%Y-%Q"); err == nil {
+		t.Errorf("Expected an unknown directive to be rejected")
+	}
+}
+
+func TestTimestamp_autoDetectDayFirst(t *testing.T) {
+	parser, _ := newTimestampParser(timestampConfig())
+	timestamp, ok := parser.parse([]string{"16/11/2010", "Thread-6", "com.test"})
+
+	if !ok || !timestamp.Equal(time.Date(2010, 11, 16, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected 16 November 2010, got %v (%v)", timestamp, ok)
+	}
+	if parser.layout != "" {
+		t.Errorf("Expected the parser to be left as it is, got the layout %q", parser.layout)
+	}
+	if _, ok := parser.parse([]string{"2010-11-16 10:00:00", "Thread-6", "com.test"}); !ok {
+		t.Errorf("Expected a row in another layout to be parsed")
+	}
+}
+
+func TestLogReader_timestampsOf_detectsTheLayoutPerFile(t *testing.T) {
+	path, cleanup := tempLog(t, "2010-11-16 10:00:00~Thread-1~com.test\n")
+	defer cleanup()
+	config := timestampConfig()
+	config.Files = append(config.Files, LogFile{LogFile: path, Name: "iso"})
+	logReader := NewLogReader(config)
+
+	if layout := logReader.timestampsOf(0).layout; layout != "02/01/2006" {
+		t.Errorf("Expected the day first layout of the first file, got %q", layout)
+	}
+	if layout := logReader.timestampsOf(1).layout; layout != "2006-01-02 15:04:05" {
+		t.Errorf("Expected the ISO layout of the second file, got %q", layout)
+	}
+}
+
+func TestLogReader_SeekTime(t *testing.T) {
+	logReader := NewLogReader(timestampConfig())
+	logReader.SetCapacity(2)
+
+	expected := [][]string{{"14/11/2010", "Thread-4", "com.test"}, {"15/11/2010", "Thread-5", "com.test"}}
+	if result := *logReader.SeekTime(time.Date(2010, 11, 14, 0, 0, 0, 0, time.UTC)); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected %s got %s`, expected, result)
+	}
+
+	expected = [][]string{{"11/11/2010", "Thread-1", "com.test"}, {"12/11/2010", "Thread-2", "com.test"}}
+	if result := *logReader.SeekTime(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected the first page %s got %s`, expected, result)
+	}
+
+	expected = [][]string{{"17/11/2010", "Thread-7", "com.test"}, {"18/11/2010", "Thread-8", "com.test"}}
+	if result := *logReader.SeekTime(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)); !reflect.DeepEqual(result, expected) {
+		t.Errorf(`Output Log: Expected the last page %s got %s`, expected, result)
+	}
+}
+
+func TestLogReader_SeekTime_timeOfDaySkipsContinuationLines(t *testing.T) {
+	config := LogReaderConfig{
+		Files:           []LogFile{{LogFile: "../test_logs/TestLogReader_Pattern.log", Name: "Name"}},
+		Pattern:         testPattern,
+		Headers:         patternHeaders(),
+		TimestampColumn: "Date",
+		TimestampLayout: "%Y-%m-%d %H:%M:%S",
+	}
+	logReader := NewLogReader(config)
+	logReader.SetCapacity(2)
+	logReader.Head()
+
+	target, err := logReader.ParseTime("10:00:04")
+	if err != nil || !target.Equal(time.Date(2010, 11, 11, 10, 0, 4, 0, time.UTC)) {
+		t.Fatalf("Expected the time of day on the date of the log, got %v (%v)", target, err)
+	}
+	result := *logReader.SeekTime(target)
+	if len(result) == 0 || result[len(result)-1][4] != "Shutting down" {
+		t.Errorf("Expected the page to show the entry at 10:00:04, got %s", result)
+	}
+}
+
+func TestLogReader_Validate_timestampColumn(t *testing.T) {
+	config := timestampConfig()
+	config.TimestampColumn = "Time"
+	if err := config.Validate(); err == nil {
+		t.Errorf("Expected an error for a timestamp column that isn't a header")
+	}
+	logReader := NewLogReader(logreaderConfig("../test_logs/TestLogReader_Tail_input.log", []int{10, 10, 10}))
+	if _, err := logReader.ParseTime("10:00"); err == nil {
+		t.Errorf("Expected an error when no timestamp column is configured")
+	}
+}